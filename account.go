@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	userInfo   = "/user_info"
+	userTrades = "/user_trades"
+)
+
+// userTradesLimit is the most trades Exmo returns for one request.
+const userTradesLimit = 100
+
+// userTradesBatch is the most pairs requested at once, which keeps the request body short.
+const userTradesBatch = 50
+
+var (
+	ErrNoCredentials = errors.New("no API credentials")
+	ErrAPI           = errors.New("exmo API error")
+)
+
+type Account interface {
+	GetUserInfo() (UserInfo, error)
+	GetUserTrades(limit, offset int, pairs ...string) (UserTrades, error)
+}
+
+// UserInfo holds the available and the reserved, by open orders, balances of every currency.
+type UserInfo struct {
+	UID        int64             `json:"uid"`
+	ServerDate int64             `json:"server_date"`
+	Balances   map[string]string `json:"balances"`
+	Reserved   map[string]string `json:"reserved"`
+}
+
+type UserTrades map[string][]UserTrade
+
+type UserTrade struct {
+	TradeID            int64     `json:"trade_id"`
+	Date               int64     `json:"date"`
+	Type               TypeTrade `json:"type"`
+	Pair               string    `json:"pair"`
+	OrderID            int64     `json:"order_id"`
+	Quantity           string    `json:"quantity"`
+	Price              string    `json:"price"`
+	Amount             string    `json:"amount"`
+	ExecType           string    `json:"exec_type"`
+	CommissionAmount   string    `json:"commission_amount"`
+	CommissionCurrency string    `json:"commission_currency"`
+	CommissionPercent  string    `json:"commission_percent"`
+}
+
+// AuthClient sends the requests of the authenticated endpoints: it adds a nonce to the body
+// and signs it with the secret.
+type AuthClient struct {
+	client *http.Client
+	key    string
+	secret string
+	now    func() time.Time
+
+	mu    sync.Mutex
+	nonce int64
+}
+
+func NewAuthClient(client *http.Client, key, secret string) *AuthClient {
+	return &AuthClient{client: client, key: key, secret: secret, now: time.Now}
+}
+
+func (c *AuthClient) GetRequest(method string, url string, body io.Reader) ([]byte, error) {
+	var form []byte
+	if body != nil {
+		var err error
+		form, err = io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("AuthClient_GetRequest -> %w", err)
+		}
+	}
+	if len(form) > 0 {
+		form = append(form, '&')
+	}
+	form = append(form, "nonce="+strconv.FormatInt(c.nextNonce(), 10)...)
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(form))
+	if err != nil {
+		return nil, fmt.Errorf("AuthClient_GetRequest -> %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Key", c.key)
+	req.Header.Set("Sign", sign(c.secret, form))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("AuthClient_GetRequest -> %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyText, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("AuthClient_GetRequest -> %w", err)
+	}
+	return bodyText, nil
+}
+
+// nextNonce returns the time in milliseconds, increased if needed to be greater than the last nonce.
+func (c *AuthClient) nextNonce() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	nonce := c.now().UnixMilli()
+	if nonce <= c.nonce {
+		nonce = c.nonce + 1
+	}
+	c.nonce = nonce
+	return nonce
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WithCredentials signs the requests of the authenticated endpoints with the API key and secret,
+// sending them with the client of WithClient.
+func WithCredentials(key, secret string) func(exmo *Exmo) {
+	return func(e *Exmo) {
+		e.key, e.secret = key, secret
+	}
+}
+
+// WithAuthRequester sets the requester of the authenticated endpoints, which must add the nonce
+// and sign the requests like AuthClient. It takes precedence over WithCredentials.
+func WithAuthRequester(requester Requester) func(exmo *Exmo) {
+	return func(e *Exmo) {
+		e.auth = requester
+	}
+}
+
+func (e *Exmo) authRequest(endpoint string, form url.Values) ([]byte, error) {
+	if e.auth == nil {
+		return nil, fmt.Errorf("Exmo_authRequest -> %w", ErrNoCredentials)
+	}
+
+	data, err := e.auth.GetRequest("POST", e.url+endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("Exmo_authRequest -> %w", err)
+	}
+
+	if err := apiError(data); err != nil {
+		return nil, fmt.Errorf("Exmo_authRequest -> %w", err)
+	}
+	return data, nil
+}
+
+// apiError returns the error reported in an Exmo response, which comes with the 200 status:
+// a false result, or the error status of candles history.
+func apiError(data []byte) error {
+	var status struct {
+		Result  *bool  `json:"result"`
+		Error   string `json:"error"`
+		Status  string `json:"s"`
+		Message string `json:"errmsg"`
+	}
+	if json.Unmarshal(data, &status) != nil {
+		return nil
+	}
+	if status.Result != nil && !*status.Result {
+		return fmt.Errorf("%s: %w", status.Error, ErrAPI)
+	}
+	if status.Status == "error" {
+		return fmt.Errorf("%s: %w", status.Message, ErrAPI)
+	}
+	return nil
+}
+
+func (e *Exmo) GetUserInfo() (UserInfo, error) {
+	data, err := e.authRequest(userInfo, url.Values{})
+	if err != nil {
+		return UserInfo{}, fmt.Errorf("Exmo_GetUserInfo -> %w", err)
+	}
+
+	userInfoResp := UserInfo{}
+	err = json.Unmarshal(data, &userInfoResp)
+	if err != nil {
+		return UserInfo{}, fmt.Errorf("Exmo_GetUserInfo -> %w", err)
+	}
+	return userInfoResp, nil
+}
+
+// GetUserTrades returns the trades of the pairs, the latest first, skipping offset of them.
+func (e *Exmo) GetUserTrades(limit, offset int, pairs ...string) (UserTrades, error) {
+	form := url.Values{}
+	form.Set("pair", strings.Join(pairs, ","))
+	form.Set("limit", strconv.Itoa(limit))
+	form.Set("offset", strconv.Itoa(offset))
+
+	data, err := e.authRequest(userTrades, form)
+	if err != nil {
+		return nil, fmt.Errorf("Exmo_GetUserTrades -> %w", err)
+	}
+
+	userTradesResp := UserTrades{}
+	err = json.Unmarshal(data, &userTradesResp)
+	if err != nil {
+		return nil, fmt.Errorf("Exmo_GetUserTrades -> %w", err)
+	}
+	return userTradesResp, nil
+}
+
+// AllUserTrades pages through the trades of every pair and returns them ordered by time. The pairs
+// are requested in batches, as the limit and the offset apply to every pair of a request, and only
+// the pairs with a full page are requested again.
+func AllUserTrades(account Account, pairs ...string) ([]UserTrade, error) {
+	res := make([]UserTrade, 0)
+	for start := 0; start < len(pairs); start += userTradesBatch {
+		batch := pairs[start:minInt(start+userTradesBatch, len(pairs))]
+		for offset := 0; len(batch) > 0; offset += userTradesLimit {
+			page, err := account.GetUserTrades(userTradesLimit, offset, batch...)
+			if err != nil {
+				return nil, fmt.Errorf("AllUserTrades -> %w", err)
+			}
+
+			full := make([]string, 0)
+			for _, pair := range batch {
+				res = append(res, page[pair]...)
+				if len(page[pair]) >= userTradesLimit {
+					full = append(full, pair)
+				}
+			}
+			batch = full
+		}
+	}
+
+	sortUserTrades(res)
+	return res, nil
+}
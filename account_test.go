@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ Account = (*Exmo)(nil)
+
+func TestAuthClient_GetRequest(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		assert.Equal(t, "key", r.Header.Get("Key"))
+		assert.Equal(t, sign("secret", body), r.Header.Get("Sign"))
+		if string(body) == "nonce=1700000000000" {
+			assert.Equal(t, "b6e352b07760e30d83b94f2412fc2b7afd8c4c76be74d8fffce2aabdbe2974b9d1388637bdc9640fec962b7919657e710dbd0d525cfdc1cb3954c3dc460a3f53",
+				r.Header.Get("Sign"))
+		}
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		fmt.Fprint(w, `{"uid":1}`)
+	}))
+	defer server.Close()
+
+	client := NewAuthClient(server.Client(), "key", "secret")
+	client.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	data, err := client.GetRequest("POST", server.URL, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"uid":1}`, string(data))
+
+	_, err = client.GetRequest("POST", server.URL, nil)
+	assert.NoError(t, err)
+	_, err = client.GetRequest("POST", server.URL, strings.NewReader("pair=BTC_USD"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"nonce=1700000000000", "nonce=1700000000001", "pair=BTC_USD&nonce=1700000000002"}, bodies)
+}
+
+func TestSign(t *testing.T) {
+	// The signature is the hex encoded HMAC-SHA512 of the body, test case 2 of RFC 4231.
+	assert.Equal(t, "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737",
+		sign("Jefe", []byte("what do ya want for nothing?")))
+	assert.Len(t, sign("secret", []byte("nonce=1")), 128)
+	assert.NotEqual(t, sign("secret", []byte("nonce=1")), sign("secret", []byte("nonce=2")))
+	assert.NotEqual(t, sign("secret", []byte("nonce=1")), sign("other", []byte("nonce=1")))
+}
+
+func TestWithCredentials(t *testing.T) {
+	httpClient := &http.Client{}
+	exmo := NewExmo(WithClient(httpClient), WithCredentials("key", "secret"))
+
+	client, ok := exmo.auth.(*AuthClient)
+	if assert.True(t, ok) {
+		assert.Equal(t, "key", client.key)
+		assert.Equal(t, "secret", client.secret)
+		assert.Same(t, httpClient, client.client)
+	}
+
+	exmo = NewExmo(WithCredentials("key", "secret"), WithClient(httpClient))
+
+	client, ok = exmo.auth.(*AuthClient)
+	if assert.True(t, ok) {
+		assert.Same(t, httpClient, client.client)
+	}
+
+	requester := RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		return []byte(`{"uid":1}`), nil
+	})
+	exmo = NewExmo(WithAuthRequester(requester), WithCredentials("key", "secret"))
+
+	_, ok = exmo.auth.(*AuthClient)
+	assert.False(t, ok)
+
+	_, err := NewExmo().GetUserInfo()
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func authExmo(responses map[string]string, requests *[]url.Values) *Exmo {
+	return NewExmo(WithAuthRequester(RequesterFunc(func(method string, rawURL string, body io.Reader) ([]byte, error) {
+		data, _ := io.ReadAll(body)
+		form, _ := url.ParseQuery(string(data))
+		if requests != nil {
+			*requests = append(*requests, form)
+		}
+		return []byte(responses[rawURL]), nil
+	})))
+}
+
+func TestExmo_GetUserInfo(t *testing.T) {
+	exmo := authExmo(map[string]string{
+		"https://api.exmo.com/v1.1/user_info": `{"uid":10542,"server_date":1435518576,"balances":{"BTC":"0.5","USD":"100"},"reserved":{"BTC":"0.1"}}`,
+	}, nil)
+
+	result, err := exmo.GetUserInfo()
+
+	assert.NoError(t, err)
+	assert.Equal(t, UserInfo{UID: 10542, ServerDate: 1435518576, Balances: map[string]string{"BTC": "0.5", "USD": "100"},
+		Reserved: map[string]string{"BTC": "0.1"}}, result)
+
+	exmo = authExmo(map[string]string{
+		"https://api.exmo.com/v1.1/user_info": `{"result":false,"error":"40017: Wrong API Key"}`,
+	}, nil)
+	_, err = exmo.GetUserInfo()
+	assert.ErrorIs(t, err, ErrAPI)
+	assert.Contains(t, err.Error(), "Wrong API Key")
+}
+
+func TestExmo_GetUserTrades(t *testing.T) {
+	var requests []url.Values
+	exmo := authExmo(map[string]string{
+		"https://api.exmo.com/v1.1/user_trades": `{"BTC_USD":[{"trade_id":3,"date":1435488248,"type":"buy","pair":"BTC_USD","quantity":"1","price":"100","amount":"100"}]}`,
+	}, &requests)
+
+	result, err := exmo.GetUserTrades(50, 10, "BTC_USD", "ETH_USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, UserTrades{"BTC_USD": {{TradeID: 3, Date: 1435488248, Type: Buy, Pair: "BTC_USD", Quantity: "1", Price: "100", Amount: "100"}}}, result)
+	assert.Equal(t, []url.Values{{"pair": {"BTC_USD,ETH_USD"}, "limit": {"50"}, "offset": {"10"}}}, requests)
+}
+
+type pagedAccount struct {
+	trades   map[string][]UserTrade
+	requests []string
+}
+
+func (a *pagedAccount) GetUserInfo() (UserInfo, error) {
+	return UserInfo{}, nil
+}
+
+func (a *pagedAccount) GetUserTrades(limit, offset int, pairs ...string) (UserTrades, error) {
+	a.requests = append(a.requests, fmt.Sprintf("%s@%d", strings.Join(pairs, ","), offset))
+	res := UserTrades{}
+	for _, pair := range pairs {
+		trades := a.trades[pair]
+		res[pair] = trades[minInt(offset, len(trades)):minInt(offset+limit, len(trades))]
+	}
+	return res, nil
+}
+
+func TestAllUserTrades(t *testing.T) {
+	account := &pagedAccount{trades: map[string][]UserTrade{}}
+	for i := 0; i < 105; i++ {
+		account.trades["BTC_USD"] = append(account.trades["BTC_USD"], UserTrade{TradeID: int64(105 - i), Date: int64(105 - i)})
+	}
+	account.trades["ETH_USD"] = []UserTrade{{TradeID: 200, Date: 200}}
+
+	trades, err := AllUserTrades(account, "BTC_USD", "ETH_USD", "XRP_USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BTC_USD,ETH_USD,XRP_USD@0", "BTC_USD@100"}, account.requests)
+	if assert.Len(t, trades, 106) {
+		assert.Equal(t, int64(1), trades[0].TradeID)
+		assert.Equal(t, int64(200), trades[105].TradeID)
+	}
+
+	pairs := make([]string, 0, userTradesBatch+1)
+	for i := 0; i <= userTradesBatch; i++ {
+		pairs = append(pairs, fmt.Sprintf("C%d_USD", i))
+	}
+	account.requests = nil
+
+	_, err = AllUserTrades(account, pairs...)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{strings.Join(pairs[:userTradesBatch], ",") + "@0", pairs[userTradesBatch] + "@0"}, account.requests)
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Triangle is a cycle of three currencies traded in this order and back to the first one.
+type Triangle [3]string
+
+// ArbitrageOpportunity is a round trip through a triangle that ends with more of the start
+// currency than it started with. Amount is the traded amount of the start currency and Profit
+// is the gain as a fraction of it, after commissions and slippage.
+type ArbitrageOpportunity struct {
+	Triangle Triangle
+	Pairs    []string
+	Amount   float64
+	Profit   float64
+	Time     time.Time
+}
+
+// tradeLeg converts from one currency of pair into the other. Selling gives up the base currency
+// for the quote one, buying gives up the quote currency for the base one.
+type tradeLeg struct {
+	pair string
+	from string
+	to   string
+	sell bool
+}
+
+// ArbitrageScanner looks for triangular arbitrage between the pairs of an exchange.
+type ArbitrageScanner struct {
+	exchange  Exchanger
+	threshold float64
+	depth     int
+	interval  time.Duration
+	amounts   map[string]float64
+	now       func() time.Time
+}
+
+type ArbitrageOption func(*ArbitrageScanner)
+
+// WithThreshold sets the least profit, as a fraction of the traded amount, of the reported opportunities.
+func WithThreshold(profit float64) ArbitrageOption {
+	return func(s *ArbitrageScanner) {
+		s.threshold = profit
+	}
+}
+
+// WithDepth sets the number of order book levels a trade may walk through, 20 by default.
+func WithDepth(depth int) ArbitrageOption {
+	return func(s *ArbitrageScanner) {
+		s.depth = depth
+	}
+}
+
+// WithInterval sets how often Run scans the exchange, every 10 seconds by default.
+func WithInterval(interval time.Duration) ArbitrageOption {
+	return func(s *ArbitrageScanner) {
+		s.interval = interval
+	}
+}
+
+// WithTradeAmounts sets the amounts of the start currencies traded round the triangles. Triangles
+// start with a currency that has an amount if there is one. The other ones are traded with
+// the largest amount the fetched order book levels fill on every leg.
+func WithTradeAmounts(amounts map[string]float64) ArbitrageOption {
+	return func(s *ArbitrageScanner) {
+		s.amounts = amounts
+	}
+}
+
+func NewArbitrageScanner(exchange Exchanger, opts ...ArbitrageOption) *ArbitrageScanner {
+	s := &ArbitrageScanner{
+		exchange: exchange,
+		depth:    20,
+		interval: 10 * time.Second,
+		amounts:  map[string]float64{},
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Triangles enumerates the currency triangles of pairs. Every triangle is returned in both
+// directions, starting with its alphabetically first currency.
+func Triangles(pairs []string) []Triangle {
+	neighbours := make(map[string]map[string]bool)
+	for _, pair := range pairs {
+		base, quote, ok := splitPair(pair)
+		if !ok {
+			continue
+		}
+		for _, link := range [][2]string{{base, quote}, {quote, base}} {
+			if neighbours[link[0]] == nil {
+				neighbours[link[0]] = make(map[string]bool)
+			}
+			neighbours[link[0]][link[1]] = true
+		}
+	}
+
+	currencies := make([]string, 0, len(neighbours))
+	for currency := range neighbours {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	res := make([]Triangle, 0)
+	for _, a := range currencies {
+		for _, b := range currencies {
+			if b <= a || !neighbours[a][b] {
+				continue
+			}
+			for _, c := range currencies {
+				if c <= a || c == b || !neighbours[b][c] || !neighbours[c][a] {
+					continue
+				}
+				res = append(res, Triangle{a, b, c})
+			}
+		}
+	}
+	return res
+}
+
+// legs returns the trades of the triangle starting with its currency start.
+func (t Triangle) legs(start int, pairs map[string]bool) []tradeLeg {
+	res := make([]tradeLeg, 0, len(t))
+	for i := 0; i < len(t); i++ {
+		from, to := t[(start+i)%len(t)], t[(start+i+1)%len(t)]
+		if pairs[from+"_"+to] {
+			res = append(res, tradeLeg{pair: from + "_" + to, from: from, to: to, sell: true})
+		} else {
+			res = append(res, tradeLeg{pair: to + "_" + from, from: from, to: to})
+		}
+	}
+	return res
+}
+
+// Scan checks every triangle once. The triangles that are profitable at the ticker prices
+// are checked against the order books, the opportunities above the threshold are returned
+// with the most profitable first.
+func (s *ArbitrageScanner) Scan() ([]ArbitrageOpportunity, error) {
+	settings, err := s.exchange.GetPairSettings()
+	if err != nil {
+		return nil, fmt.Errorf("ArbitrageScanner_Scan -> %w", err)
+	}
+	tickerResp, err := s.exchange.GetTicker()
+	if err != nil {
+		return nil, fmt.Errorf("ArbitrageScanner_Scan -> %w", err)
+	}
+
+	pairs := make(map[string]bool, len(settings))
+	pairNames := make([]string, 0, len(settings))
+	commissions := make(map[string]float64, len(settings))
+	for pair, setting := range settings {
+		if _, ok := tickerResp[pair]; !ok {
+			continue
+		}
+		commission, err := parsePrice(setting.CommissionTakerPercent)
+		if err != nil {
+			return nil, fmt.Errorf("ArbitrageScanner_Scan -> %s commission: %w", pair, err)
+		}
+		pairs[pair], commissions[pair] = true, commission/100
+		pairNames = append(pairNames, pair)
+	}
+
+	type candidate struct {
+		triangle Triangle
+		legs     []tradeLeg
+	}
+	candidates := make([]candidate, 0)
+	bookPairs := make(map[string]bool)
+	for _, triangle := range Triangles(pairNames) {
+		legs := triangle.legs(s.start(triangle), pairs)
+		rate := 1.0
+		for _, leg := range legs {
+			price := tickerResp[leg.pair].SellPrice
+			if leg.sell {
+				price = tickerResp[leg.pair].BuyPrice
+			}
+			legRate, err := strconv.ParseFloat(price, 64)
+			if err != nil || legRate <= 0 {
+				rate = 0
+				break
+			}
+			if !leg.sell {
+				legRate = 1 / legRate
+			}
+			rate *= legRate * (1 - commissions[leg.pair])
+		}
+		if rate-1 <= s.threshold {
+			continue
+		}
+
+		candidates = append(candidates, candidate{triangle: triangle, legs: legs})
+		for _, leg := range legs {
+			bookPairs[leg.pair] = true
+		}
+	}
+	if len(candidates) == 0 {
+		return []ArbitrageOpportunity{}, nil
+	}
+
+	books, err := s.exchange.GetOrderBook(s.depth, sortedKeys(bookPairs)...)
+	if err != nil {
+		return nil, fmt.Errorf("ArbitrageScanner_Scan -> %w", err)
+	}
+
+	now := s.now()
+	res := make([]ArbitrageOpportunity, 0)
+	for _, c := range candidates {
+		amount := s.amounts[c.legs[0].from]
+		if amount <= 0 {
+			if amount, err = maxAmount(books, c.legs, commissions); err != nil {
+				return nil, fmt.Errorf("ArbitrageScanner_Scan -> %w", err)
+			}
+			if amount <= 0 {
+				continue
+			}
+		}
+
+		value, ok, err := roundTrip(books, c.legs, amount, commissions)
+		if err != nil {
+			return nil, fmt.Errorf("ArbitrageScanner_Scan -> %w", err)
+		}
+		if !ok {
+			continue
+		}
+
+		profit := value/amount - 1
+		if profit <= s.threshold {
+			continue
+		}
+
+		opportunity := ArbitrageOpportunity{Amount: amount, Profit: profit, Time: now}
+		for i := range c.legs {
+			opportunity.Triangle[i] = c.legs[i].from
+			opportunity.Pairs = append(opportunity.Pairs, c.legs[i].pair)
+		}
+		res = append(res, opportunity)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Profit > res[j].Profit })
+	return res, nil
+}
+
+// start returns the index of the currency a triangle starts with: the first one with a trade amount.
+func (s *ArbitrageScanner) start(triangle Triangle) int {
+	for i, currency := range triangle {
+		if s.amounts[currency] > 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// Run scans the exchange every interval and passes the opportunities to onOpportunities, when
+// there are any, until ctx is done. Scan errors are passed to onError, if it is set, and the scan
+// is retried on the next tick. Once ctx is done, no scan is started and no callback is called.
+func (s *ArbitrageScanner) Run(ctx context.Context, onOpportunities func([]ArbitrageOpportunity), onError func(error)) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		// A tick and the cancellation can be ready at once, and select picks either.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		opportunities, err := s.Scan()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && onError != nil {
+			onError(fmt.Errorf("ArbitrageScanner_Run -> %w", err))
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if len(opportunities) > 0 && onOpportunities != nil {
+			onOpportunities(opportunities)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// roundTrip trades amount through legs against the order books and returns the amount received
+// at the end. It is not ok if the books are too thin for the amount.
+func roundTrip(books OrderBook, legs []tradeLeg, amount float64, commissions map[string]float64) (float64, bool, error) {
+	value := amount
+	for _, leg := range legs {
+		book, ok := books[leg.pair]
+		if !ok {
+			return 0, false, nil
+		}
+
+		received, ok, err := fill(leg.levels(book), value, leg.sell)
+		if err != nil {
+			return 0, false, fmt.Errorf("%s: %w", leg.pair, err)
+		}
+		if !ok {
+			return 0, false, nil
+		}
+		value = received * (1 - commissions[leg.pair])
+	}
+	return value, true, nil
+}
+
+// maxAmount returns the largest amount of the start currency of legs that the levels of the order
+// books fill on every leg. It works back from the last leg, the amount every leg can spend caps
+// the amount the leg before it may receive. The amount is kept just under the limit, so rounding
+// does not leave the round trip short of a level.
+func maxAmount(books OrderBook, legs []tradeLeg, commissions map[string]float64) (float64, error) {
+	received := math.Inf(1)
+	for i := len(legs) - 1; i >= 0; i-- {
+		book, ok := books[legs[i].pair]
+		if !ok {
+			return 0, nil
+		}
+
+		spent, err := spend(legs[i].levels(book), received, legs[i].sell)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", legs[i].pair, err)
+		}
+		if i == 0 {
+			return spent * (1 - 1e-9), nil
+		}
+		received = spent / (1 - commissions[legs[i-1].pair])
+	}
+	return 0, nil
+}
+
+// levels returns the side of book the leg trades against: the bids when selling, the asks when buying.
+func (l tradeLeg) levels(book OrderBookPair) [][]string {
+	if l.sell {
+		return book.Bid
+	}
+	return book.Ask
+}
+
+// fill trades amount against the order book levels of [price, quantity, amount]. Selling spends
+// the base currency on the bids, buying spends the quote currency on the asks.
+func fill(levels [][]string, amount float64, sell bool) (float64, bool, error) {
+	var received float64
+	for _, level := range levels {
+		price, quantity, err := parseLevel(level)
+		if err != nil {
+			return 0, false, err
+		}
+		if price <= 0 {
+			continue
+		}
+
+		if sell {
+			traded := minFloat(amount, quantity)
+			received += traded * price
+			amount -= traded
+		} else {
+			spent := minFloat(amount, quantity*price)
+			received += spent / price
+			amount -= spent
+		}
+		if amount <= 0 {
+			return received, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// spend is the inverse of fill: it returns the amount to spend on the levels to receive received,
+// or all that the levels can take when they cannot give that much.
+func spend(levels [][]string, received float64, sell bool) (float64, error) {
+	var spent float64
+	for _, level := range levels {
+		price, quantity, err := parseLevel(level)
+		if err != nil {
+			return 0, err
+		}
+		if price <= 0 {
+			continue
+		}
+
+		if sell {
+			got := minFloat(received, quantity*price)
+			spent += got / price
+			received -= got
+		} else {
+			got := minFloat(received, quantity)
+			spent += got * price
+			received -= got
+		}
+		if received <= 0 {
+			break
+		}
+	}
+	return spent, nil
+}
+
+func parseLevel(level []string) (float64, float64, error) {
+	if len(level) < 2 {
+		return 0, 0, fmt.Errorf("invalid order book level %v", level)
+	}
+	price, err := strconv.ParseFloat(level[0], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	quantity, err := strconv.ParseFloat(level[1], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return price, quantity, nil
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func sortedKeys(set map[string]bool) []string {
+	res := make([]string, 0, len(set))
+	for key := range set {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
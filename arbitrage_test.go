@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func arbitrageExchange(t *testing.T) *Exmo {
+	settings := PairSettings{
+		"BTC_USD": {CommissionTakerPercent: "0.4"},
+		"ETH_USD": {CommissionTakerPercent: "0.4"},
+		"ETH_BTC": {CommissionTakerPercent: "0.4"},
+		"XRP_USD": {CommissionTakerPercent: "0.4"},
+	}
+	tickerResp := Ticker{
+		"BTC_USD": {BuyPrice: "30000", SellPrice: "30010"},
+		"ETH_USD": {BuyPrice: "2100", SellPrice: "2101"},
+		"ETH_BTC": {BuyPrice: "0.065", SellPrice: "0.0651"},
+		"XRP_USD": {BuyPrice: "0.5", SellPrice: "0.51"},
+	}
+	books := OrderBook{
+		"BTC_USD": {Ask: [][]string{{"30010", "1", "30010"}, {"31000", "10", "310000"}}, Bid: [][]string{{"30000", "1", "30000"}}},
+		"ETH_USD": {Ask: [][]string{{"2101", "5", "10505"}}, Bid: [][]string{{"2100", "5", "10500"}, {"2000", "100", "200000"}}},
+		"ETH_BTC": {Ask: [][]string{{"0.0651", "10", "0.651"}, {"0.066", "100", "6.6"}}, Bid: [][]string{{"0.065", "10", "0.65"}}},
+	}
+
+	return NewExmo(WithRequester(RequesterFunc(func(method string, rawURL string, body io.Reader) ([]byte, error) {
+		switch {
+		case strings.HasSuffix(rawURL, pairSettings):
+			return json.Marshal(settings)
+		case strings.HasSuffix(rawURL, ticker):
+			return json.Marshal(tickerResp)
+		case strings.HasSuffix(rawURL, orderBook):
+			data, _ := io.ReadAll(body)
+			values, _ := url.ParseQuery(string(data))
+			assert.Equal(t, "5", values.Get("limit"))
+			pair := values.Get("pair")
+			return json.Marshal(OrderBook{pair: books[pair]})
+		}
+		return nil, errors.New("unexpected request " + rawURL)
+	})))
+}
+
+func TestTriangles(t *testing.T) {
+	triangles := Triangles([]string{"BTC_USD", "ETH_USD", "ETH_BTC", "XRP_USD", "invalid"})
+
+	assert.Equal(t, []Triangle{{"BTC", "ETH", "USD"}, {"BTC", "USD", "ETH"}}, triangles)
+}
+
+func TestArbitrageScanner_Scan(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	commission := 0.996
+
+	t.Run("order book depth", func(t *testing.T) {
+		scanner := NewArbitrageScanner(arbitrageExchange(t), WithDepth(5), WithThreshold(-0.05))
+		scanner.now = func() time.Time { return now }
+
+		opportunities, err := scanner.Scan()
+
+		assert.NoError(t, err)
+		if assert.Len(t, opportunities, 1) {
+			// The 105 ETH of the bids cap the trade: buying them takes both levels of ETH_BTC,
+			// and their 209658 USD take both levels of BTC_USD.
+			btc := 0.651 + (105/commission-10)*0.066
+			usd := (5*2100 + 100*2000) * commission
+			received := (1 + (usd-30010)/31000) * commission
+			assert.Equal(t, Triangle{"BTC", "ETH", "USD"}, opportunities[0].Triangle)
+			assert.Equal(t, []string{"ETH_BTC", "ETH_USD", "BTC_USD"}, opportunities[0].Pairs)
+			assert.InDelta(t, btc, opportunities[0].Amount, 1e-6)
+			assert.InDelta(t, received/btc-1, opportunities[0].Profit, 1e-6)
+			assert.Equal(t, now, opportunities[0].Time)
+		}
+
+		// The triangle is profitable at the ticker prices, but not through the depth of the books.
+		scanner = NewArbitrageScanner(arbitrageExchange(t), WithDepth(5), WithThreshold(0.01))
+		opportunities, err = scanner.Scan()
+		assert.NoError(t, err)
+		assert.Empty(t, opportunities)
+	})
+
+	t.Run("slippage", func(t *testing.T) {
+		scanner := NewArbitrageScanner(arbitrageExchange(t), WithDepth(5), WithThreshold(0.01),
+			WithTradeAmounts(map[string]float64{"USD": 30000}))
+
+		opportunities, err := scanner.Scan()
+
+		assert.NoError(t, err)
+		if assert.Len(t, opportunities, 1) {
+			// 30000 USD buy 0.99967 BTC, which buy 10 ETH and the rest at the second level, sold at two levels.
+			btc := (1 + (30000-30010)/30010.0) * commission
+			eth := (10 + (btc-0.651)/0.066) * commission
+			usd := (5*2100 + (eth-5)*2000) * commission
+			assert.Equal(t, Triangle{"USD", "BTC", "ETH"}, opportunities[0].Triangle)
+			assert.Equal(t, 30000.0, opportunities[0].Amount)
+			assert.InDelta(t, usd/30000-1, opportunities[0].Profit, 1e-9)
+		}
+
+		scanner = NewArbitrageScanner(arbitrageExchange(t), WithDepth(5), WithThreshold(0.05),
+			WithTradeAmounts(map[string]float64{"USD": 30000}))
+		opportunities, err = scanner.Scan()
+		assert.NoError(t, err)
+		assert.Empty(t, opportunities)
+	})
+
+	t.Run("thin books", func(t *testing.T) {
+		scanner := NewArbitrageScanner(arbitrageExchange(t), WithDepth(5), WithTradeAmounts(map[string]float64{"BTC": 100}))
+
+		opportunities, err := scanner.Scan()
+
+		assert.NoError(t, err)
+		assert.Empty(t, opportunities)
+	})
+}
+
+func TestArbitrageScanner_Run(t *testing.T) {
+	scanner := NewArbitrageScanner(arbitrageExchange(t), WithDepth(5), WithThreshold(-0.05), WithInterval(time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var scans int
+	err := scanner.Run(ctx, func(opportunities []ArbitrageOpportunity) {
+		scans++
+		if scans == 2 {
+			cancel()
+		}
+	}, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, scans)
+}
+
+func TestArbitrageScanner_Run_canceled(t *testing.T) {
+	scanner := NewArbitrageScanner(arbitrageExchange(t), WithInterval(time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := scanner.Run(ctx, func(opportunities []ArbitrageOpportunity) {
+		t.Error("unexpected opportunities")
+	}, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_maxAmount(t *testing.T) {
+	books := OrderBook{
+		"BTC_USD": {Ask: [][]string{{"100", "1", "100"}, {"200", "1", "200"}}, Bid: [][]string{{"90", "2", "180"}}},
+	}
+	buy := []tradeLeg{{pair: "BTC_USD", from: "USD", to: "BTC"}}
+	sell := []tradeLeg{{pair: "BTC_USD", from: "BTC", to: "USD", sell: true}}
+
+	amount, err := maxAmount(books, buy, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 300, amount, 1e-6)
+	received, ok, err := roundTrip(books, buy, amount, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 2, received, 1e-6)
+
+	amount, err = maxAmount(books, sell, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, amount, 1e-6)
+
+	amount, err = maxAmount(books, []tradeLeg{{pair: "ETH_USD", from: "USD", to: "ETH"}}, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, amount)
+}
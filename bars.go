@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type BarType int
+
+const (
+	// TimeBars close a bar when a trade falls into the next time interval.
+	TimeBars BarType = iota
+	// TickBars close a bar after a fixed number of trades.
+	TickBars
+	// VolumeBars close a bar once the traded quantity reaches the threshold.
+	VolumeBars
+	// DollarBars close a bar once the traded quote amount reaches the threshold.
+	DollarBars
+)
+
+var ErrInvalidThreshold = errors.New("threshold must be positive")
+
+// BarBuilder aggregates trades into candles. It can be fed a whole batch with BuildBars
+// or one trade at a time from a streaming feed with Add.
+type BarBuilder struct {
+	barType   BarType
+	threshold float64
+	interval  time.Duration
+
+	current *Candle
+	ticks   int
+	amount  float64
+}
+
+func NewTimeBarBuilder(interval time.Duration) (*BarBuilder, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("NewTimeBarBuilder -> %w", ErrInvalidThreshold)
+	}
+	return &BarBuilder{barType: TimeBars, interval: interval}, nil
+}
+
+// NewBarBuilder returns a builder of bars closing at threshold: a number of trades for tick bars,
+// a quantity for volume bars, a quote amount for dollar bars and a number of seconds for time bars.
+func NewBarBuilder(barType BarType, threshold float64) (*BarBuilder, error) {
+	if barType == TimeBars {
+		return NewTimeBarBuilder(time.Duration(threshold * float64(time.Second)))
+	}
+	if threshold <= 0 {
+		return nil, fmt.Errorf("NewBarBuilder -> %w", ErrInvalidThreshold)
+	}
+	return &BarBuilder{barType: barType, threshold: threshold}, nil
+}
+
+// Add adds a trade to the current bar and returns the bar closed by it, if any.
+// Trades must be added in chronological order.
+func (b *BarBuilder) Add(trade Pair) (Candle, bool, error) {
+	price, err := strconv.ParseFloat(trade.Price, 64)
+	if err != nil {
+		return Candle{}, false, fmt.Errorf("BarBuilder_Add -> %w", err)
+	}
+	quantity, err := strconv.ParseFloat(trade.Quantity, 64)
+	if err != nil {
+		return Candle{}, false, fmt.Errorf("BarBuilder_Add -> %w", err)
+	}
+	amount, err := strconv.ParseFloat(trade.Amount, 64)
+	if err != nil {
+		amount = price * quantity
+	}
+
+	var closed Candle
+	var ok bool
+	if b.barType == TimeBars {
+		start := time.Unix(trade.Date, 0).Truncate(b.interval).UnixMilli()
+		if b.current != nil && b.current.T != start {
+			closed, ok = b.Flush()
+		}
+		if b.current == nil {
+			b.current = &Candle{T: start, O: price, H: price, L: price}
+		}
+	} else if b.current == nil {
+		b.current = &Candle{T: trade.Date * 1000, O: price, H: price, L: price}
+	}
+
+	if price > b.current.H {
+		b.current.H = price
+	}
+	if price < b.current.L {
+		b.current.L = price
+	}
+	b.current.C = price
+	b.current.V += quantity
+	b.ticks++
+	b.amount += amount
+
+	if b.full() {
+		closed, ok = b.Flush()
+	}
+	return closed, ok, nil
+}
+
+// Flush closes the current bar even if it has not reached its threshold.
+func (b *BarBuilder) Flush() (Candle, bool) {
+	if b.current == nil {
+		return Candle{}, false
+	}
+
+	closed := *b.current
+	b.current, b.ticks, b.amount = nil, 0, 0
+	return closed, true
+}
+
+func (b *BarBuilder) full() bool {
+	switch b.barType {
+	case TickBars:
+		return float64(b.ticks) >= b.threshold
+	case VolumeBars:
+		return b.current.V >= b.threshold
+	case DollarBars:
+		return b.amount >= b.threshold
+	}
+	return false
+}
+
+// BuildBars aggregates a batch of trades in chronological order and flushes the last bar.
+func BuildBars(trades []Pair, b *BarBuilder) ([]Candle, error) {
+	sorted := make([]Pair, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Date != sorted[j].Date {
+			return sorted[i].Date < sorted[j].Date
+		}
+		return sorted[i].TradeID < sorted[j].TradeID
+	})
+
+	res := make([]Candle, 0)
+	for _, trade := range sorted {
+		candle, ok, err := b.Add(trade)
+		if err != nil {
+			return nil, fmt.Errorf("BuildBars -> %w", err)
+		}
+		if ok {
+			res = append(res, candle)
+		}
+	}
+	if candle, ok := b.Flush(); ok {
+		res = append(res, candle)
+	}
+	return res, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testTrades = []Pair{
+	{TradeID: 4, Date: 1701367861, Type: Buy, Quantity: "1", Price: "12", Amount: "12"},
+	{TradeID: 1, Date: 1701367800, Type: Buy, Quantity: "2", Price: "10", Amount: "20"},
+	{TradeID: 2, Date: 1701367810, Type: Sell, Quantity: "1", Price: "9", Amount: "9"},
+	{TradeID: 3, Date: 1701367830, Type: Buy, Quantity: "3", Price: "11", Amount: "33"},
+	{TradeID: 5, Date: 1701367990, Type: Sell, Quantity: "1", Price: "8", Amount: "8"},
+}
+
+func TestNewBarBuilder(t *testing.T) {
+	result, err := NewBarBuilder(TimeBars, 60)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, result.interval)
+
+	_, err = NewBarBuilder(VolumeBars, 0)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+
+	_, err = NewTimeBarBuilder(0)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+}
+
+func TestBuildBars(t *testing.T) {
+	type testData struct {
+		barType   BarType
+		threshold float64
+		expected  []Candle
+	}
+
+	testCases := []testData{
+		{barType: TimeBars, threshold: 60, expected: []Candle{
+			{T: 1701367800000, O: 10, H: 11, L: 9, C: 11, V: 6},
+			{T: 1701367860000, O: 12, H: 12, L: 12, C: 12, V: 1},
+			{T: 1701367980000, O: 8, H: 8, L: 8, C: 8, V: 1},
+		}},
+		{barType: TickBars, threshold: 2, expected: []Candle{
+			{T: 1701367800000, O: 10, H: 10, L: 9, C: 9, V: 3},
+			{T: 1701367830000, O: 11, H: 12, L: 11, C: 12, V: 4},
+			{T: 1701367990000, O: 8, H: 8, L: 8, C: 8, V: 1},
+		}},
+		{barType: VolumeBars, threshold: 3, expected: []Candle{
+			{T: 1701367800000, O: 10, H: 10, L: 9, C: 9, V: 3},
+			{T: 1701367830000, O: 11, H: 11, L: 11, C: 11, V: 3},
+			{T: 1701367861000, O: 12, H: 12, L: 8, C: 8, V: 2},
+		}},
+		{barType: DollarBars, threshold: 40, expected: []Candle{
+			{T: 1701367800000, O: 10, H: 11, L: 9, C: 11, V: 6},
+			{T: 1701367861000, O: 12, H: 12, L: 8, C: 8, V: 2},
+		}},
+	}
+
+	for _, tc := range testCases {
+		builder, err := NewBarBuilder(tc.barType, tc.threshold)
+		assert.NoError(t, err)
+
+		result, err := BuildBars(testTrades, builder)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, result)
+	}
+
+	builder, _ := NewBarBuilder(TickBars, 1)
+	_, err := BuildBars([]Pair{{Price: "invalid", Quantity: "1"}}, builder)
+	assert.Error(t, err)
+}
+
+func TestBarBuilder_Add(t *testing.T) {
+	builder, err := NewBarBuilder(TickBars, 2)
+	assert.NoError(t, err)
+
+	_, ok, err := builder.Add(Pair{Date: 1701367800, Quantity: "1", Price: "10"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	result, ok, err := builder.Add(Pair{Date: 1701367801, Quantity: "1", Price: "11"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Candle{T: 1701367800000, O: 10, H: 11, L: 10, C: 11, V: 2}, result)
+
+	_, ok = builder.Flush()
+	assert.False(t, ok)
+}
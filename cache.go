@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// NoExpiration keeps a cached response until it is evicted by the size bound.
+const NoExpiration time.Duration = -1
+
+type Cache struct {
+	requester  Requester
+	ttls       map[string]time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	calls   map[string]*cacheCall
+}
+
+type cacheEntry struct {
+	key     string
+	data    []byte
+	expires time.Time
+}
+
+type cacheCall struct {
+	wg   sync.WaitGroup
+	data []byte
+	err  error
+}
+
+type CacheOption func(*Cache)
+
+// NewCache wraps requester with an LRU cache of public market data. Only endpoints with a TTL
+// are cached: currencies for a day, pair settings for an hour and the ticker for a few seconds.
+// Candles history is cached for a minute, or forever once every candle of the requested range
+// is closed.
+func NewCache(requester Requester, opts ...CacheOption) *Cache {
+	c := &Cache{
+		requester: requester,
+		ttls: map[string]time.Duration{
+			currency:       24 * time.Hour,
+			pairSettings:   time.Hour,
+			ticker:         5 * time.Second,
+			candlesHistory: time.Minute,
+		},
+		maxEntries: 1000,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		calls:      make(map[string]*cacheCall),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithTTL sets the TTL of an endpoint such as "/ticker". A zero TTL disables caching of the endpoint.
+func WithTTL(endpoint string, ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.ttls[endpoint] = ttl
+	}
+}
+
+func WithMaxEntries(maxEntries int) CacheOption {
+	return func(c *Cache) {
+		c.maxEntries = maxEntries
+	}
+}
+
+func CacheMiddleware(opts ...CacheOption) Middleware {
+	return func(r Requester) Requester {
+		return NewCache(r, opts...)
+	}
+}
+
+func (c *Cache) GetRequest(method string, url string, body io.Reader) ([]byte, error) {
+	ttl := c.ttl(url)
+	if ttl == 0 {
+		return c.requester.GetRequest(method, url, body)
+	}
+
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("Cache_GetRequest -> %w", err)
+		}
+		body = bytes.NewReader(bodyBytes)
+	}
+	key := requestKey(method, url, string(bodyBytes))
+
+	c.mu.Lock()
+	if data, ok := c.get(key); ok {
+		c.mu.Unlock()
+		return data, nil
+	}
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		call.wg.Wait()
+		if call.err != nil {
+			return nil, call.err
+		}
+		return copyBytes(call.data), nil
+	}
+	call := &cacheCall{}
+	call.wg.Add(1)
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	call.data, call.err = c.requester.GetRequest(method, url, body)
+	if call.err != nil {
+		call.err = fmt.Errorf("Cache_GetRequest -> %w", call.err)
+	}
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	if call.err == nil && !isErrorResponse(call.data) {
+		c.set(key, copyBytes(call.data), ttl)
+	}
+	c.mu.Unlock()
+	call.wg.Done()
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	return copyBytes(call.data), nil
+}
+
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache) get(key string) ([]byte, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return copyBytes(entry.data), true
+}
+
+func (c *Cache) set(key string, data []byte, ttl time.Duration) {
+	entry := &cacheEntry{key: key, data: data}
+	if ttl != NoExpiration {
+		entry.expires = c.now().Add(ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[key] = c.order.PushFront(entry)
+	}
+
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *Cache) ttl(rawURL string) time.Duration {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+
+	endpoint := "/" + path.Base(u.Path)
+	ttl := c.ttls[endpoint]
+	if endpoint == candlesHistory && ttl != 0 && c.candlesClosed(u.Query()) {
+		return NoExpiration
+	}
+	return ttl
+}
+
+// candlesClosed reports whether the last candle of a candles history request has already closed,
+// which makes the response immutable. The resolution is given in minutes.
+func (c *Cache) candlesClosed(query url.Values) bool {
+	resolution, err := strconv.Atoi(query.Get("resolution"))
+	if err != nil {
+		return false
+	}
+	to, err := strconv.ParseInt(query.Get("to"), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	closeTime := time.Unix(to, 0).Add(time.Duration(resolution) * time.Minute)
+	return !c.now().Before(closeTime)
+}
+
+// isErrorResponse reports whether data is an API error. Exmo reports errors with the 200 status,
+// so they would otherwise be cached like any other response.
+func isErrorResponse(data []byte) bool {
+	return apiError(data) != nil
+}
+
+func copyBytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	res := make([]byte, len(data))
+	copy(res, data)
+	return res
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCache(t *testing.T) {
+	result := NewCache(&MockClient{}, WithTTL(trades, time.Second), WithMaxEntries(10))
+
+	assert.Equal(t, time.Second, result.ttls[trades])
+	assert.Equal(t, 24*time.Hour, result.ttls[currency])
+	assert.Equal(t, 10, result.maxEntries)
+}
+
+func TestCache_GetRequest(t *testing.T) {
+	var calls int
+	requester := RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		calls++
+		if url == "https://api.exmo.com/v1.1/currency?fail=true" {
+			return nil, errors.New("request failed")
+		}
+		return []byte(url), nil
+	})
+	now := time.Unix(1701367794, 0)
+	cache := NewCache(requester, WithMaxEntries(2))
+	cache.now = func() time.Time { return now }
+
+	type testData struct {
+		url           string
+		expected      string
+		advance       time.Duration
+		expectedCalls int
+		expectedErr   bool
+	}
+
+	testCases := []testData{
+		{url: "https://api.exmo.com/v1.1/ticker", expectedCalls: 1},
+		{url: "https://api.exmo.com/v1.1/ticker", advance: time.Second, expectedCalls: 1},
+		{url: "https://api.exmo.com/v1.1/ticker", advance: 5 * time.Second, expectedCalls: 2},
+		{url: "https://api.exmo.com/v1.1/trades", expectedCalls: 3},
+		{url: "https://api.exmo.com/v1.1/trades", expectedCalls: 4},
+		{url: "https://api.exmo.com/v1.1/currency?fail=true", expectedCalls: 5, expectedErr: true},
+		{url: "https://api.exmo.com/v1.1/currency?fail=true", expectedCalls: 6, expectedErr: true},
+		{url: "https://api.exmo.com/v1.1/candles_history?symbol=ADA_BTC&resolution=30&from=1701289470&to=1701293070", expectedCalls: 7},
+		{url: "https://api.exmo.com/v1.1/candles_history?to=1701293070&from=1701289470&symbol=ADA_BTC&resolution=30", expected: "https://api.exmo.com/v1.1/candles_history?symbol=ADA_BTC&resolution=30&from=1701289470&to=1701293070", advance: 24 * time.Hour, expectedCalls: 7},
+		{url: "https://api.exmo.com/v1.1/candles_history?symbol=ADA_BTC&resolution=30&from=1701289470&to=1801293070", expectedCalls: 8},
+		{url: "https://api.exmo.com/v1.1/candles_history?symbol=ADA_BTC&resolution=30&from=1701289470&to=1801293070", advance: time.Minute, expectedCalls: 9},
+	}
+
+	for _, tc := range testCases {
+		now = now.Add(tc.advance)
+		result, err := cache.GetRequest("GET", tc.url, nil)
+		if tc.expectedErr {
+			assert.Error(t, err)
+			assert.Nil(t, result)
+		} else {
+			expected := tc.expected
+			if expected == "" {
+				expected = tc.url
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(result))
+		}
+		assert.Equal(t, tc.expectedCalls, calls, tc.url)
+		assert.LessOrEqual(t, cache.Len(), 2)
+	}
+}
+
+func TestCache_GetRequest_evictsLeastRecentlyUsed(t *testing.T) {
+	var calls int
+	requester := RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		calls++
+		return []byte(url), nil
+	})
+	cache := NewCache(requester, WithMaxEntries(2))
+
+	for _, url := range []string{"https://exmo/ticker?a", "https://exmo/ticker?b", "https://exmo/ticker?a", "https://exmo/ticker?c", "https://exmo/ticker?a"} {
+		_, err := cache.GetRequest("POST", url, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, calls)
+
+	_, err := cache.GetRequest("POST", "https://exmo/ticker?b", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestCache_GetRequest_skipsErrorResponses(t *testing.T) {
+	var calls int
+	requester := RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		calls++
+		if calls == 1 {
+			return []byte(`{"result":false,"error":"Error 40016: Maintenance work in progress"}`), nil
+		}
+		return []byte(`{"s":"ok","candles":[]}`), nil
+	})
+	cache := NewCache(requester)
+	url := "https://api.exmo.com/v1.1/candles_history?symbol=ADA_BTC&resolution=30&from=1701289470&to=1701293070"
+
+	result, err := cache.GetRequest("GET", url, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"result":false,"error":"Error 40016: Maintenance work in progress"}`, string(result))
+	assert.Equal(t, 0, cache.Len())
+
+	for i := 0; i < 2; i++ {
+		result, err = cache.GetRequest("GET", url, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"s":"ok","candles":[]}`, string(result))
+	}
+	assert.Equal(t, 2, calls)
+
+	assert.True(t, isErrorResponse([]byte(`{"s":"error","errmsg":"Error 40016: Maintenance work in progress"}`)))
+	assert.False(t, isErrorResponse([]byte(`{"result":true}`)))
+	assert.False(t, isErrorResponse([]byte(`["BTC","USD"]`)))
+}
+
+func TestCache_GetRequest_deduplicatesConcurrentRequests(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	requester := RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return []byte("data"), nil
+	})
+	cache := NewCache(requester)
+
+	var wg sync.WaitGroup
+	results := make([][]byte, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = cache.GetRequest("POST", "https://api.exmo.com/v1.1/currency", nil)
+		}(i)
+	}
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, result := range results {
+		assert.Equal(t, []byte("data"), result)
+	}
+}
+
+func TestCacheMiddleware(t *testing.T) {
+	requester := Chain(&MockClient{}, CacheMiddleware(WithMaxEntries(1)))
+
+	cache, ok := requester.(*Cache)
+	assert.True(t, ok)
+	assert.Equal(t, 1, cache.maxEntries)
+}
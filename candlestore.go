@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type CandleStore struct {
+	dir string
+	now func() time.Time
+	mu  sync.Mutex
+}
+
+type candleFile struct {
+	Ranges  []candleRange `json:"ranges"`
+	Candles []Candle      `json:"candles"`
+}
+
+// candleRange is an inclusive range of unix seconds whose closed candles are all stored.
+type candleRange struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+func NewCandleStore(dir string) (*CandleStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("CandleStore_NewCandleStore -> %w", err)
+	}
+	return &CandleStore{dir: dir, now: time.Now}, nil
+}
+
+// Candles returns the candles of pair between start and end from the API at source, its base URL.
+// Ranges that are already on disk are served locally, the rest is requested with fetch and the closed
+// candles are saved for later calls. The store is not locked while fetching, so several pairs can
+// be synced at once. The resolution is given in minutes, as in Exmo.GetCandlesHistory.
+func (s *CandleStore) Candles(source, pair string, resolution int, start, end time.Time, fetch func(start, end time.Time) ([]Candle, error)) ([]Candle, error) {
+	path := s.path(source, pair, resolution)
+	s.mu.Lock()
+	stored, err := readCandleFile(path)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("CandleStore_Candles -> %w", err)
+	}
+
+	from, to := start.Unix(), end.Unix()
+	gaps := missingRanges(stored.Ranges, from, to)
+	fetched := make([][]Candle, 0, len(gaps))
+	for _, gap := range gaps {
+		candles, err := fetch(time.Unix(gap.From, 0), time.Unix(gap.To, 0))
+		if err != nil {
+			return nil, fmt.Errorf("CandleStore_Candles -> %w", err)
+		}
+		fetched = append(fetched, candles)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The file is read again, as other calls may have saved candles while fetching.
+	if len(gaps) > 0 {
+		if stored, err = readCandleFile(path); err != nil {
+			return nil, fmt.Errorf("CandleStore_Candles -> %w", err)
+		}
+	}
+
+	candles := make(map[int64]Candle, len(stored.Candles))
+	for _, candle := range stored.Candles {
+		candles[candle.T] = candle
+	}
+
+	lastClosed := s.now().Add(-time.Duration(resolution) * time.Minute).Unix()
+	changed := false
+	for i, gap := range gaps {
+		for _, candle := range fetched[i] {
+			candles[candle.T] = candle
+		}
+		if gap.From <= lastClosed {
+			stored.Ranges = addRange(stored.Ranges, candleRange{From: gap.From, To: minInt64(gap.To, lastClosed)})
+			changed = true
+		}
+	}
+
+	res := make([]Candle, 0)
+	stored.Candles = stored.Candles[:0]
+	for _, candle := range candles {
+		if candle.T/1000 <= lastClosed {
+			stored.Candles = append(stored.Candles, candle)
+		}
+		if candle.T/1000 >= from && candle.T/1000 <= to {
+			res = append(res, candle)
+		}
+	}
+	sortCandles(res)
+
+	if changed {
+		sortCandles(stored.Candles)
+		if err := writeCandleFile(path, stored); err != nil {
+			return nil, fmt.Errorf("CandleStore_Candles -> %w", err)
+		}
+	}
+	return res, nil
+}
+
+// path keeps the candles of every source in its own directory, named after the escaped URL.
+func (s *CandleStore) path(source, pair string, resolution int) string {
+	return filepath.Join(s.dir, url.QueryEscape(source), pair+"_"+strconv.Itoa(resolution)+".json")
+}
+
+func readCandleFile(path string) (candleFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return candleFile{}, nil
+		}
+		return candleFile{}, err
+	}
+
+	var file candleFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return candleFile{}, err
+	}
+	return file, nil
+}
+
+func writeCandleFile(path string, file candleFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// missingRanges returns the parts of [from, to] that are not covered by the sorted ranges.
+func missingRanges(ranges []candleRange, from, to int64) []candleRange {
+	var res []candleRange
+	for _, r := range ranges {
+		if r.To < from {
+			continue
+		}
+		if r.From > to {
+			break
+		}
+		if r.From > from {
+			res = append(res, candleRange{From: from, To: r.From - 1})
+		}
+		from = r.To + 1
+	}
+	if from <= to {
+		res = append(res, candleRange{From: from, To: to})
+	}
+	return res
+}
+
+// addRange inserts r into the sorted ranges and merges the ranges it touches.
+func addRange(ranges []candleRange, r candleRange) []candleRange {
+	ranges = append(ranges, r)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].From < ranges[j].From })
+
+	res := ranges[:1]
+	for _, next := range ranges[1:] {
+		last := &res[len(res)-1]
+		if next.From <= last.To+1 {
+			if next.To > last.To {
+				last.To = next.To
+			}
+			continue
+		}
+		res = append(res, next)
+	}
+	return res
+}
+
+func sortCandles(candles []Candle) {
+	sort.Slice(candles, func(i, j int) bool { return candles[i].T < candles[j].T })
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCandleStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "candles")
+
+	result, err := NewCandleStore(dir)
+
+	assert.NoError(t, err)
+	assert.Equal(t, dir, result.dir)
+	_, err = os.Stat(dir)
+	assert.NoError(t, err)
+}
+
+func TestCandleStore_Candles(t *testing.T) {
+	store, err := NewCandleStore(t.TempDir())
+	assert.NoError(t, err)
+	store.now = func() time.Time { return time.Unix(10000, 0) }
+
+	var requested []candleRange
+	fetch := func(start, end time.Time) ([]Candle, error) {
+		requested = append(requested, candleRange{From: start.Unix(), To: end.Unix()})
+		var res []Candle
+		for ts := (start.Unix() + 59) / 60 * 60; ts <= end.Unix(); ts += 60 {
+			res = append(res, Candle{T: ts * 1000, C: float64(ts)})
+		}
+		return res, nil
+	}
+
+	type testData struct {
+		from, to          int64
+		expectedRequested []candleRange
+		expectedLen       int
+	}
+
+	testCases := []testData{
+		{from: 600, to: 1200, expectedRequested: []candleRange{{600, 1200}}, expectedLen: 11},
+		{from: 600, to: 1200, expectedRequested: nil, expectedLen: 11},
+		{from: 300, to: 1500, expectedRequested: []candleRange{{300, 599}, {1201, 1500}}, expectedLen: 21},
+		{from: 9000, to: 10000, expectedRequested: []candleRange{{9000, 10000}}, expectedLen: 17},
+		{from: 9000, to: 10000, expectedRequested: []candleRange{{9941, 10000}}, expectedLen: 17},
+	}
+
+	for _, tc := range testCases {
+		requested = nil
+		result, err := store.Candles("https://api.exmo.com/v1.1", "ADA_BTC", 1, time.Unix(tc.from, 0), time.Unix(tc.to, 0), fetch)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expectedRequested, requested)
+		assert.Len(t, result, tc.expectedLen)
+		for i := 1; i < len(result); i++ {
+			assert.Less(t, result[i-1].T, result[i].T)
+		}
+	}
+
+	reopened, err := NewCandleStore(store.dir)
+	assert.NoError(t, err)
+	reopened.now = store.now
+	result, err := reopened.Candles("https://api.exmo.com/v1.1", "ADA_BTC", 1, time.Unix(300, 0), time.Unix(1500, 0), func(start, end time.Time) ([]Candle, error) {
+		return nil, errors.New("unexpected request")
+	})
+	assert.NoError(t, err)
+	assert.Len(t, result, 21)
+
+	_, err = reopened.Candles("https://sandbox.exmo.com/v1.1", "ADA_BTC", 1, time.Unix(300, 0), time.Unix(1500, 0), func(start, end time.Time) ([]Candle, error) {
+		return nil, errors.New("request failed")
+	})
+	assert.Error(t, err)
+
+	_, err = reopened.Candles("https://api.exmo.com/v1.1", "ADA_USD", 1, time.Unix(300, 0), time.Unix(1500, 0), func(start, end time.Time) ([]Candle, error) {
+		return nil, errors.New("request failed")
+	})
+	assert.Error(t, err)
+}
+
+func TestCandleStore_Candles_fetchesConcurrently(t *testing.T) {
+	store, err := NewCandleStore(t.TempDir())
+	assert.NoError(t, err)
+	store.now = func() time.Time { return time.Unix(10000, 0) }
+
+	var started sync.WaitGroup
+	started.Add(2)
+	fetch := func(start, end time.Time) ([]Candle, error) {
+		started.Done()
+		started.Wait()
+		return []Candle{{T: 600000, C: 1}}, nil
+	}
+
+	var wg sync.WaitGroup
+	for _, pair := range []string{"ADA_BTC", "ADA_USD"} {
+		wg.Add(1)
+		go func(pair string) {
+			defer wg.Done()
+			result, err := store.Candles("https://api.exmo.com/v1.1", pair, 1, time.Unix(600, 0), time.Unix(1200, 0), fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, []Candle{{T: 600000, C: 1}}, result)
+		}(pair)
+	}
+	wg.Wait()
+}
+
+func TestWithCandleStore(t *testing.T) {
+	store, err := NewCandleStore(t.TempDir())
+	assert.NoError(t, err)
+
+	var calls int
+	requester := RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		calls++
+		return []byte(`{"candles":[{"t":1701289500000,"c":1},{"t":1701291300000,"c":2}]}`), nil
+	})
+	exmo := NewExmo(WithRequester(requester), WithCandleStore(store))
+	assert.Equal(t, store, exmo.store)
+
+	for i := 0; i < 2; i++ {
+		result, err := exmo.GetCandlesHistory("ADA_BTC", 30, time.Unix(1701289470, 0), time.Unix(1701293070, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, CandlesHistory{Candles: []Candle{{T: 1701289500000, C: 1}, {T: 1701291300000, C: 2}}}, result)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithCandleStore_errorResponses(t *testing.T) {
+	store, err := NewCandleStore(t.TempDir())
+	assert.NoError(t, err)
+
+	responses := []string{
+		`{"result":false,"error":"Error 40016: Maintenance work in progress"}`,
+		`{"s":"error","errmsg":"Error 40016: Maintenance work in progress"}`,
+		`{"candles":[{"t":1701289500000,"c":1}]}`,
+	}
+	var calls int
+	requester := RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		calls++
+		return []byte(responses[minInt(calls, len(responses))-1]), nil
+	})
+	exmo := NewExmo(WithRequester(requester), WithCandleStore(store))
+
+	for i := 0; i < 2; i++ {
+		_, err = exmo.GetCandlesHistory("ADA_BTC", 30, time.Unix(1701289470, 0), time.Unix(1701293070, 0))
+		assert.ErrorIs(t, err, ErrAPI)
+		assert.Contains(t, err.Error(), "Exmo_GetCandlesHistory -> CandleStore_Candles -> Exmo_fetchCandlesHistory -> Error 40016")
+	}
+
+	// The failed requests cover no range, so the candles are requested again.
+	for i := 0; i < 2; i++ {
+		result, err := exmo.GetCandlesHistory("ADA_BTC", 30, time.Unix(1701289470, 0), time.Unix(1701293070, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []Candle{{T: 1701289500000, C: 1}}, result.Candles)
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func Test_missingRanges(t *testing.T) {
+	ranges := []candleRange{{10, 20}, {30, 40}}
+
+	assert.Equal(t, []candleRange{{0, 9}, {21, 29}, {41, 50}}, missingRanges(ranges, 0, 50))
+	assert.Equal(t, []candleRange(nil), missingRanges(ranges, 12, 18))
+	assert.Equal(t, []candleRange{{21, 25}}, missingRanges(ranges, 15, 25))
+	assert.Equal(t, []candleRange{{0, 5}}, missingRanges(nil, 0, 5))
+}
+
+func Test_addRange(t *testing.T) {
+	ranges := []candleRange{{10, 20}, {30, 40}}
+
+	assert.Equal(t, []candleRange{{10, 40}}, addRange(ranges, candleRange{21, 29}))
+	assert.Equal(t, []candleRange{{0, 5}, {10, 20}, {30, 40}}, addRange([]candleRange{{10, 20}, {30, 40}}, candleRange{0, 5}))
+	assert.Equal(t, []candleRange{{1, 2}}, addRange(nil, candleRange{1, 2}))
+}
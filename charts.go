@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ChartTransform converts candles into the candles of another chart type, so that indicators
+// can run on it.
+type ChartTransform func(candles []Candle) ([]Candle, error)
+
+// WithChart makes the candle-based indicators run on the candles converted by transform.
+func WithChart(transform ChartTransform) IndicatorOption {
+	return func(i *Indicator) {
+		i.chart = transform
+	}
+}
+
+// HeikinAshi averages every candle with the previous Heikin-Ashi candle: the close is the average
+// of the prices of the candle and the open is the middle of the previous Heikin-Ashi body.
+func HeikinAshi(candles []Candle) []Candle {
+	res := make([]Candle, 0, len(candles))
+	for j, candle := range candles {
+		ha := Candle{T: candle.T, V: candle.V, C: (candle.O + candle.H + candle.L + candle.C) / 4}
+		if j == 0 {
+			ha.O = (candle.O + candle.C) / 2
+		} else {
+			ha.O = (res[j-1].O + res[j-1].C) / 2
+		}
+		ha.H = math.Max(candle.H, math.Max(ha.O, ha.C))
+		ha.L = math.Min(candle.L, math.Min(ha.O, ha.C))
+		res = append(res, ha)
+	}
+	return res
+}
+
+// Renko builds bricks of boxSize from the closes of candles, starting at the first close.
+// A brick in the direction of the last one needs a move of one box beyond it, a reversal
+// needs two. Every brick is a candle with the time of the candle that completed it and
+// the volume traded since the previous brick.
+func Renko(candles []Candle, boxSize float64) ([]Candle, error) {
+	if boxSize <= 0 || math.IsNaN(boxSize) || math.IsInf(boxSize, 0) {
+		return nil, fmt.Errorf("Renko -> box size %v: %w", boxSize, ErrInvalidParams)
+	}
+
+	res := make([]Candle, 0)
+	if len(candles) == 0 {
+		return res, nil
+	}
+
+	top, bottom := candles[0].C, candles[0].C
+	var volume float64
+	for _, candle := range candles[1:] {
+		volume += candle.V
+		for candle.C >= top+boxSize {
+			res = append(res, brick(candle.T, top, top+boxSize, volume))
+			bottom, top, volume = top, top+boxSize, 0
+		}
+		for candle.C <= bottom-boxSize {
+			res = append(res, brick(candle.T, bottom, bottom-boxSize, volume))
+			top, bottom, volume = bottom, bottom-boxSize, 0
+		}
+	}
+	return res, nil
+}
+
+// RenkoATR is Renko with the box size of the last ATR of candles.
+func RenkoATR(candles []Candle, period int) ([]Candle, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("RenkoATR -> %w", ErrInvalidPeriod)
+	}
+
+	atr := calculateATR(candles, period)
+	if len(atr) == 0 || math.IsNaN(atr[len(atr)-1]) {
+		return nil, fmt.Errorf("RenkoATR -> not enough candles for ATR(%d): %w", period, ErrInvalidParams)
+	}
+
+	res, err := Renko(candles, atr[len(atr)-1])
+	if err != nil {
+		return nil, fmt.Errorf("RenkoATR -> %w", err)
+	}
+	return res, nil
+}
+
+func brick(t int64, open, close, volume float64) Candle {
+	return Candle{T: t, O: open, C: close, H: math.Max(open, close), L: math.Min(open, close), V: volume}
+}
+
+// KagiLine is a vertical line of a Kagi chart from Start to End, started by the candle at T.
+// Yang lines are the thick ones: a line turns yang when it rises above the previous shoulder
+// and yin when it falls below the previous waist.
+type KagiLine struct {
+	T     int64
+	Start float64
+	End   float64
+	Yang  bool
+}
+
+// Kagi builds a Kagi chart from the closes of candles. A line reverses when the close moves
+// against it by at least reversal from its end. The last line is not finished yet.
+func Kagi(candles []Candle, reversal float64) ([]KagiLine, error) {
+	if reversal <= 0 || math.IsNaN(reversal) || math.IsInf(reversal, 0) {
+		return nil, fmt.Errorf("Kagi -> reversal %v: %w", reversal, ErrInvalidParams)
+	}
+
+	res := make([]KagiLine, 0)
+	if len(candles) == 0 {
+		return res, nil
+	}
+
+	start := candles[0]
+	shoulder, waist := math.Inf(1), math.Inf(-1)
+	for _, candle := range candles[1:] {
+		if len(res) == 0 {
+			if math.Abs(candle.C-start.C) >= reversal {
+				res = append(res, KagiLine{T: start.T, Start: start.C, End: candle.C, Yang: candle.C > start.C})
+			}
+			continue
+		}
+
+		line := &res[len(res)-1]
+		rising := line.End > line.Start
+		switch {
+		case rising && candle.C > line.End, !rising && candle.C < line.End:
+			line.End = candle.C
+		case rising && line.End-candle.C >= reversal:
+			shoulder = line.End
+			res = append(res, KagiLine{T: candle.T, Start: line.End, End: candle.C, Yang: line.Yang})
+		case !rising && candle.C-line.End >= reversal:
+			waist = line.End
+			res = append(res, KagiLine{T: candle.T, Start: line.End, End: candle.C, Yang: line.Yang})
+		default:
+			continue
+		}
+
+		line = &res[len(res)-1]
+		if line.End > shoulder {
+			line.Yang = true
+		} else if line.End < waist {
+			line.Yang = false
+		}
+	}
+	return res, nil
+}
+
+// KagiCandles converts Kagi lines into candles opening at the start of the line and closing at its end.
+func KagiCandles(lines []KagiLine) []Candle {
+	res := make([]Candle, 0, len(lines))
+	for _, line := range lines {
+		res = append(res, brick(line.T, line.Start, line.End, 0))
+	}
+	return res
+}
+
+// PointAndFigureColumn is a column of Xs (rising) or Os (falling) between the Low and the High
+// box, started by the candle at T.
+type PointAndFigureColumn struct {
+	T     int64
+	Low   float64
+	High  float64
+	Boxes int
+	X     bool
+}
+
+// boxEpsilon keeps prices that are a whole number of boxes from being rounded to the box below.
+const boxEpsilon = 1e-9
+
+// PointAndFigure builds a Point-and-Figure chart from the closes of candles on the grid of
+// boxSize. A column continues with every whole box in its direction and reverses when
+// the close moves reversal boxes against it.
+func PointAndFigure(candles []Candle, boxSize float64, reversal int) ([]PointAndFigureColumn, error) {
+	if boxSize <= 0 || math.IsNaN(boxSize) || math.IsInf(boxSize, 0) || reversal <= 0 {
+		return nil, fmt.Errorf("PointAndFigure -> box size %v, reversal %d: %w", boxSize, reversal, ErrInvalidParams)
+	}
+
+	res := make([]PointAndFigureColumn, 0)
+	if len(candles) == 0 {
+		return res, nil
+	}
+
+	boxBelow := func(price float64) float64 { return math.Floor(price/boxSize+boxEpsilon) * boxSize }
+	boxAbove := func(price float64) float64 { return math.Ceil(price/boxSize-boxEpsilon) * boxSize }
+	column := func(t int64, low, high float64, x bool) PointAndFigureColumn {
+		return PointAndFigureColumn{T: t, Low: low, High: high, Boxes: int(math.Round((high-low)/boxSize)) + 1, X: x}
+	}
+
+	base := boxBelow(candles[0].C)
+	for _, candle := range candles[1:] {
+		if len(res) == 0 {
+			if high := boxBelow(candle.C); high >= base+boxSize {
+				res = append(res, column(candle.T, base, high, true))
+			} else if low := boxAbove(candle.C); low <= base-boxSize {
+				res = append(res, column(candle.T, low, base, false))
+			}
+			continue
+		}
+
+		last := &res[len(res)-1]
+		switch {
+		case last.X && boxBelow(candle.C) > last.High:
+			*last = column(last.T, last.Low, boxBelow(candle.C), true)
+		case !last.X && boxAbove(candle.C) < last.Low:
+			*last = column(last.T, boxAbove(candle.C), last.High, false)
+		case last.X && boxAbove(candle.C) <= last.High-float64(reversal)*boxSize:
+			res = append(res, column(candle.T, boxAbove(candle.C), last.High-boxSize, false))
+		case !last.X && boxBelow(candle.C) >= last.Low+float64(reversal)*boxSize:
+			res = append(res, column(candle.T, last.Low+boxSize, boxBelow(candle.C), true))
+		}
+	}
+	return res, nil
+}
+
+// PointAndFigureCandles converts columns into candles, rising from Low to High for the X columns
+// and falling from High to Low for the O columns.
+func PointAndFigureCandles(columns []PointAndFigureColumn) []Candle {
+	res := make([]Candle, 0, len(columns))
+	for _, column := range columns {
+		if column.X {
+			res = append(res, brick(column.T, column.Low, column.High, 0))
+		} else {
+			res = append(res, brick(column.T, column.High, column.Low, 0))
+		}
+	}
+	return res
+}
+
+func HeikinAshiTransform() ChartTransform {
+	return func(candles []Candle) ([]Candle, error) {
+		return HeikinAshi(candles), nil
+	}
+}
+
+func RenkoTransform(boxSize float64) ChartTransform {
+	return func(candles []Candle) ([]Candle, error) {
+		return Renko(candles, boxSize)
+	}
+}
+
+func RenkoATRTransform(period int) ChartTransform {
+	return func(candles []Candle) ([]Candle, error) {
+		return RenkoATR(candles, period)
+	}
+}
+
+func KagiTransform(reversal float64) ChartTransform {
+	return func(candles []Candle) ([]Candle, error) {
+		lines, err := Kagi(candles, reversal)
+		if err != nil {
+			return nil, err
+		}
+		return KagiCandles(lines), nil
+	}
+}
+
+func PointAndFigureTransform(boxSize float64, reversal int) ChartTransform {
+	return func(candles []Candle) ([]Candle, error) {
+		columns, err := PointAndFigure(candles, boxSize, reversal)
+		if err != nil {
+			return nil, err
+		}
+		return PointAndFigureCandles(columns), nil
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeikinAshi(t *testing.T) {
+	candles := []Candle{{T: 0, O: 10, H: 12, L: 9, C: 11, V: 1}, {T: 60000, O: 11, H: 13, L: 10, C: 12, V: 2}}
+
+	assert.Equal(t, []Candle{
+		{T: 0, O: 10.5, H: 12, L: 9, C: 10.5, V: 1},
+		{T: 60000, O: 10.5, H: 13, L: 10, C: 11.5, V: 2},
+	}, HeikinAshi(candles))
+}
+
+func TestRenko(t *testing.T) {
+	candles := closeCandles(10, 11, 12.5, 11.5, 10.9, 9.5, 13)
+
+	bricks, err := Renko(candles, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Candle{
+		{T: 60000, O: 10, C: 11, H: 11, L: 10, V: 1},
+		{T: 120000, O: 11, C: 12, H: 12, L: 11, V: 1},
+		{T: 300000, O: 11, C: 10, H: 11, L: 10, V: 3},
+		{T: 360000, O: 11, C: 12, H: 12, L: 11, V: 1},
+		{T: 360000, O: 12, C: 13, H: 13, L: 12, V: 0},
+	}, bricks)
+
+	_, err = Renko(candles, 0)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestRenkoATR(t *testing.T) {
+	candles := closeCandles(10, 11, 12, 13, 14)
+
+	// Every true range is 2, so the box is 2.
+	bricks, err := RenkoATR(candles, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Candle{
+		{T: 120000, O: 10, C: 12, H: 12, L: 10, V: 2},
+		{T: 240000, O: 12, C: 14, H: 14, L: 12, V: 2},
+	}, bricks)
+
+	_, err = RenkoATR(candles, 10)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestKagi(t *testing.T) {
+	candles := closeCandles(10, 11, 13, 12, 10.5, 9, 12, 14, 10, 8, 11)
+
+	lines, err := Kagi(candles, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []KagiLine{
+		{T: 0, Start: 10, End: 13, Yang: true},
+		{T: 240000, Start: 13, End: 9, Yang: true},
+		{T: 360000, Start: 9, End: 14, Yang: true},
+		{T: 480000, Start: 14, End: 8, Yang: false},
+		{T: 600000, Start: 8, End: 11, Yang: false},
+	}, lines)
+
+	assert.Equal(t, Candle{T: 240000, O: 13, C: 9, H: 13, L: 9}, KagiCandles(lines)[1])
+
+	_, err = Kagi(candles, -1)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestPointAndFigure(t *testing.T) {
+	candles := closeCandles(10, 10.5, 11.2, 13, 11, 10, 9.4, 12.9, 14)
+
+	columns, err := PointAndFigure(candles, 1, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PointAndFigureColumn{
+		{T: 120000, Low: 10, High: 13, Boxes: 4, X: true},
+		{T: 300000, Low: 10, High: 12, Boxes: 3, X: false},
+		{T: 480000, Low: 11, High: 14, Boxes: 4, X: true},
+	}, columns)
+
+	assert.Equal(t, Candle{T: 300000, O: 12, C: 10, H: 12, L: 10}, PointAndFigureCandles(columns)[1])
+
+	_, err = PointAndFigure(candles, 1, 0)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestWithChart(t *testing.T) {
+	exchange := candlesExchange(`[{"t":0,"o":10,"c":11,"h":12,"l":9,"v":1},{"t":60000,"o":11,"c":12,"h":13,"l":10,"v":2}]`)
+	indicator := NewIndicator(exchange, WithChart(HeikinAshiTransform()))
+
+	candles, err := indicator.GetCandles("BTC_USD", 1, time.Unix(0, 0), time.Unix(120, 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, HeikinAshi([]Candle{{T: 0, O: 10, C: 11, H: 12, L: 9, V: 1}, {T: 60000, O: 11, C: 12, H: 13, L: 10, V: 2}}), candles)
+
+	sma, err := indicator.SMA("BTC_USD", 1, 1, time.Unix(0, 0), time.Unix(120, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{11}, sma)
+
+	indicator = NewIndicator(exchange, WithChart(RenkoTransform(0)))
+	_, err = indicator.GetCandles("BTC_USD", 1, time.Unix(0, 0), time.Unix(120, 0))
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
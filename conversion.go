@@ -0,0 +1,246 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrNoConversionPath = errors.New("no conversion path")
+
+// defaultMaxHops is the default of the most pairs a conversion path goes through.
+const defaultMaxHops = 3
+
+// Conversion is the best way to convert From into To found in a ConversionGraph. Path holds
+// the currencies from From to To and Pairs the pairs traded on the way. Rate is the amount
+// of To received for one From, after fees.
+type Conversion struct {
+	From  string
+	To    string
+	Rate  float64
+	Path  []string
+	Pairs []string
+}
+
+// ConversionGraph links the currencies that are traded in a pair with the rates of selling the base
+// currency at the best bid and buying it at the best ask.
+type ConversionGraph struct {
+	edges   map[string][]conversionEdge
+	fee     float64
+	fees    map[string]float64
+	maxHops int
+}
+
+type conversionEdge struct {
+	to   string
+	pair string
+	rate float64
+}
+
+type ConversionOption func(*ConversionGraph)
+
+// WithFee sets the fee, as a fraction of the received amount, taken by every trade of a conversion.
+func WithFee(fee float64) ConversionOption {
+	return func(g *ConversionGraph) {
+		g.fee = fee
+	}
+}
+
+// WithPairFees sets the fees of pairs, overriding the fee set by WithFee.
+func WithPairFees(fees map[string]float64) ConversionOption {
+	return func(g *ConversionGraph) {
+		g.fees = fees
+	}
+}
+
+// WithMaxHops limits the number of pairs a conversion path goes through, 3 by default.
+func WithMaxHops(hops int) ConversionOption {
+	return func(g *ConversionGraph) {
+		g.maxHops = hops
+	}
+}
+
+func newConversionGraph(opts []ConversionOption) *ConversionGraph {
+	g := &ConversionGraph{edges: make(map[string][]conversionEdge), maxHops: defaultMaxHops}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// NewConversionGraph builds the graph from the buy (best bid) and sell (best ask) prices of ticker.
+func NewConversionGraph(ticker Ticker, opts ...ConversionOption) (*ConversionGraph, error) {
+	g := newConversionGraph(opts)
+	for pair, value := range ticker {
+		if err := g.addPair(pair, value.BuyPrice, value.SellPrice); err != nil {
+			return nil, fmt.Errorf("NewConversionGraph -> %w", err)
+		}
+	}
+	g.sortEdges()
+	return g, nil
+}
+
+// NewConversionGraphFromOrderBook builds the graph from the tops of the order book.
+func NewConversionGraphFromOrderBook(book OrderBook, opts ...ConversionOption) (*ConversionGraph, error) {
+	g := newConversionGraph(opts)
+	for pair, value := range book {
+		if err := g.addPair(pair, value.BidTop, value.AskTop); err != nil {
+			return nil, fmt.Errorf("NewConversionGraphFromOrderBook -> %w", err)
+		}
+	}
+	g.sortEdges()
+	return g, nil
+}
+
+// LoadConversionGraph builds the graph from the ticker of exchange.
+func LoadConversionGraph(exchange Exchanger, opts ...ConversionOption) (*ConversionGraph, error) {
+	ticker, err := exchange.GetTicker()
+	if err != nil {
+		return nil, fmt.Errorf("LoadConversionGraph -> %w", err)
+	}
+
+	g, err := NewConversionGraph(ticker, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConversionGraph -> %w", err)
+	}
+	return g, nil
+}
+
+func splitPair(pair string) (string, string, bool) {
+	base, quote, ok := strings.Cut(pair, "_")
+	return base, quote, ok && base != "" && quote != ""
+}
+
+// addPair adds the edges of pair: selling base at bid and buying base at ask. Prices that are
+// empty or zero mean there are no orders on that side, so the edge is not added.
+func (g *ConversionGraph) addPair(pair, bid, ask string) error {
+	base, quote, ok := splitPair(pair)
+	if !ok {
+		return fmt.Errorf("invalid pair %q", pair)
+	}
+
+	bidPrice, err := parsePrice(bid)
+	if err != nil {
+		return fmt.Errorf("%s bid: %w", pair, err)
+	}
+	askPrice, err := parsePrice(ask)
+	if err != nil {
+		return fmt.Errorf("%s ask: %w", pair, err)
+	}
+
+	if bidPrice > 0 {
+		g.edges[base] = append(g.edges[base], conversionEdge{to: quote, pair: pair, rate: bidPrice})
+	}
+	if askPrice > 0 {
+		g.edges[quote] = append(g.edges[quote], conversionEdge{to: base, pair: pair, rate: 1 / askPrice})
+	}
+	return nil
+}
+
+func parsePrice(price string) (float64, error) {
+	if price == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(price, 64)
+}
+
+// sortEdges makes the search independent of the map iteration order.
+func (g *ConversionGraph) sortEdges() {
+	for _, edges := range g.edges {
+		sort.Slice(edges, func(i, j int) bool { return edges[i].pair < edges[j].pair })
+	}
+}
+
+func (g *ConversionGraph) pairFee(pair string) float64 {
+	if fee, ok := g.fees[pair]; ok {
+		return fee
+	}
+	return g.fee
+}
+
+// Currencies returns the currencies of the graph in alphabetical order.
+func (g *ConversionGraph) Currencies() []string {
+	seen := make(map[string]bool)
+	for from, edges := range g.edges {
+		seen[from] = true
+		for _, edge := range edges {
+			seen[edge.to] = true
+		}
+	}
+
+	res := make([]string, 0, len(seen))
+	for currency := range seen {
+		res = append(res, currency)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Convert finds the path from one currency to another with the best rate after fees, through
+// no more than the max hops pairs and visiting every currency once.
+func (g *ConversionGraph) Convert(from, to string) (Conversion, error) {
+	if from == to {
+		return Conversion{From: from, To: to, Rate: 1, Path: []string{from}, Pairs: []string{}}, nil
+	}
+
+	best := Conversion{}
+	current := []Conversion{{From: from, To: from, Rate: 1, Path: []string{from}, Pairs: []string{}}}
+	for hop := 0; hop < g.maxHops && len(current) > 0; hop++ {
+		bestAt := make(map[string]Conversion)
+		for _, conversion := range current {
+			for _, edge := range g.edges[conversion.To] {
+				if contains(conversion.Path, edge.to) {
+					continue
+				}
+
+				next := Conversion{
+					From:  from,
+					To:    edge.to,
+					Rate:  conversion.Rate * edge.rate * (1 - g.pairFee(edge.pair)),
+					Path:  append(append(make([]string, 0, len(conversion.Path)+1), conversion.Path...), edge.to),
+					Pairs: append(append(make([]string, 0, len(conversion.Pairs)+1), conversion.Pairs...), edge.pair),
+				}
+				if edge.to == to {
+					if next.Rate > best.Rate {
+						best = next
+					}
+					continue
+				}
+				if other, ok := bestAt[edge.to]; !ok || next.Rate > other.Rate {
+					bestAt[edge.to] = next
+				}
+			}
+		}
+
+		current = make([]Conversion, 0, len(bestAt))
+		for _, conversion := range bestAt {
+			current = append(current, conversion)
+		}
+		sort.Slice(current, func(i, j int) bool { return current[i].To < current[j].To })
+	}
+
+	if best.Path == nil {
+		return Conversion{}, fmt.Errorf("ConversionGraph_Convert -> %s to %s: %w", from, to, ErrNoConversionPath)
+	}
+	return best, nil
+}
+
+// Value converts amount of from into to.
+func (g *ConversionGraph) Value(amount float64, from, to string) (float64, error) {
+	conversion, err := g.Convert(from, to)
+	if err != nil {
+		return 0, fmt.Errorf("ConversionGraph_Value -> %w", err)
+	}
+	return amount * conversion.Rate, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var conversionTicker = Ticker{
+	"BTC_USD": {BuyPrice: "30000", SellPrice: "30100"},
+	"ETH_USD": {BuyPrice: "2000", SellPrice: "2010"},
+	"ETH_BTC": {BuyPrice: "0.07", SellPrice: "0.0701"},
+	"XRP_BTC": {BuyPrice: "0.00002", SellPrice: "0"},
+}
+
+func TestConversionGraph_Convert(t *testing.T) {
+	type testData struct {
+		name     string
+		opts     []ConversionOption
+		from, to string
+		rate     float64
+		path     []string
+		pairs    []string
+	}
+
+	testCases := []testData{
+		{name: "direct", from: "BTC", to: "USD", rate: 30000, path: []string{"BTC", "USD"}, pairs: []string{"BTC_USD"}},
+		{name: "inverse", from: "USD", to: "ETH", rate: 1 / 2010.0, path: []string{"USD", "ETH"}, pairs: []string{"ETH_USD"}},
+		{name: "intermediate", from: "ETH", to: "USD", rate: 2100, path: []string{"ETH", "BTC", "USD"}, pairs: []string{"ETH_BTC", "BTC_USD"}},
+		{name: "fee", opts: []ConversionOption{WithFee(0.01)}, from: "ETH", to: "USD", rate: 2100 * 0.99 * 0.99,
+			path: []string{"ETH", "BTC", "USD"}, pairs: []string{"ETH_BTC", "BTC_USD"}},
+		{name: "pair fee", opts: []ConversionOption{WithFee(0.01), WithPairFees(map[string]float64{"ETH_BTC": 0.1})}, from: "ETH", to: "USD",
+			rate: 1980, path: []string{"ETH", "USD"}, pairs: []string{"ETH_USD"}},
+		{name: "one-sided pair", from: "XRP", to: "USD", rate: 0.6, path: []string{"XRP", "BTC", "USD"}, pairs: []string{"XRP_BTC", "BTC_USD"}},
+		{name: "same currency", from: "USD", to: "USD", rate: 1, path: []string{"USD"}, pairs: []string{}},
+	}
+
+	for _, tc := range testCases {
+		graph, err := NewConversionGraph(conversionTicker, tc.opts...)
+		if !assert.NoError(t, err, tc.name) {
+			continue
+		}
+
+		conversion, err := graph.Convert(tc.from, tc.to)
+
+		assert.NoError(t, err, tc.name)
+		assert.InDelta(t, tc.rate, conversion.Rate, 1e-9, tc.name)
+		assert.Equal(t, tc.path, conversion.Path, tc.name)
+		assert.Equal(t, tc.pairs, conversion.Pairs, tc.name)
+	}
+}
+
+func TestConversionGraph_Convert_noPath(t *testing.T) {
+	graph, err := NewConversionGraph(conversionTicker)
+	assert.NoError(t, err)
+
+	_, err = graph.Convert("USD", "XRP")
+	assert.ErrorIs(t, err, ErrNoConversionPath)
+	_, err = graph.Convert("USD", "DOGE")
+	assert.ErrorIs(t, err, ErrNoConversionPath)
+
+	graph, err = NewConversionGraph(conversionTicker, WithMaxHops(1))
+	assert.NoError(t, err)
+	_, err = graph.Convert("XRP", "USD")
+	assert.ErrorIs(t, err, ErrNoConversionPath)
+}
+
+func TestConversionGraph_Value(t *testing.T) {
+	graph, err := NewConversionGraph(conversionTicker)
+	assert.NoError(t, err)
+
+	value, err := graph.Value(2, "BTC", "USD")
+	assert.NoError(t, err)
+	assert.InDelta(t, 60000, value, 1e-9)
+	assert.Equal(t, []string{"BTC", "ETH", "USD", "XRP"}, graph.Currencies())
+}
+
+func TestNewConversionGraph_errors(t *testing.T) {
+	_, err := NewConversionGraph(Ticker{"BTC_USD": {BuyPrice: "abc", SellPrice: "1"}})
+	assert.Error(t, err)
+
+	_, err = NewConversionGraph(Ticker{"BTCUSD": {BuyPrice: "1", SellPrice: "1"}})
+	assert.Error(t, err)
+}
+
+func TestNewConversionGraphFromOrderBook(t *testing.T) {
+	graph, err := NewConversionGraphFromOrderBook(OrderBook{"BTC_USD": {BidTop: "30000", AskTop: "30100"}})
+	assert.NoError(t, err)
+
+	conversion, err := graph.Convert("USD", "BTC")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1/30100.0, conversion.Rate, 1e-12)
+}
+
+func TestLoadConversionGraph(t *testing.T) {
+	exchange := NewExmo(WithRequester(RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		return []byte(`{"BTC_USD":{"buy_price":"30000","sell_price":"30100"}}`), nil
+	})))
+
+	graph, err := LoadConversionGraph(exchange)
+	assert.NoError(t, err)
+
+	value, err := graph.Value(0.5, "BTC", "USD")
+	assert.NoError(t, err)
+	assert.InDelta(t, 15000, value, 1e-9)
+}
@@ -15,10 +15,14 @@ const (
 	orderBook      = "/order_book"
 	currency       = "/currency"
 	candlesHistory = "/candles_history"
+	pairSettings   = "/pair_settings"
 )
 
+// CandlesHistory holds the candles of the requested range. Synthesized holds the timestamps
+// of the candles added by gap filling, in milliseconds like Candle.T.
 type CandlesHistory struct {
-	Candles []Candle `json:"candles"`
+	Candles     []Candle `json:"candles"`
+	Synthesized []int64  `json:"-"`
 }
 
 type Candle struct {
@@ -59,6 +63,21 @@ type TickerValue struct {
 	Updated   int64  `json:"updated"`
 }
 
+// PairSettings are the trading limits and the commissions, in percent, of every pair.
+type PairSettings map[string]PairSetting
+
+type PairSetting struct {
+	MinQuantity            string `json:"min_quantity"`
+	MaxQuantity            string `json:"max_quantity"`
+	MinPrice               string `json:"min_price"`
+	MaxPrice               string `json:"max_price"`
+	MinAmount              string `json:"min_amount"`
+	MaxAmount              string `json:"max_amount"`
+	PricePrecision         int    `json:"price_precision"`
+	CommissionTakerPercent string `json:"commission_taker_percent"`
+	CommissionMakerPercent string `json:"commission_maker_percent"`
+}
+
 type Trades map[string][]Pair
 
 type Pair struct {
@@ -82,16 +101,28 @@ type Exmo struct {
 	url       string
 	isTest    bool
 	requester Requester
+	store     *CandleStore
+	fillGaps  bool
+	fill      FillPolicy
+	key       string
+	secret    string
+	auth      Requester
 }
 
 func NewExmo(opts ...func(exmo *Exmo)) *Exmo {
 	e := &Exmo{client: &http.Client{}, url: "https://api.exmo.com/v1.1"}
-	e.requester = NewClient(e.client)
 	for _, option := range opts {
 		option(e)
 	}
-	if e.isTest {
-		e.requester = &MockClient{}
+	if e.requester == nil {
+		if e.isTest {
+			e.requester = &MockClient{}
+		} else {
+			e.requester = NewClient(e.client)
+		}
+	}
+	if e.auth == nil && e.key != "" {
+		e.auth = NewAuthClient(e.client, e.key, e.secret)
 	}
 	return e
 }
@@ -102,6 +133,26 @@ func WithClient(client *http.Client) func(exmo *Exmo) {
 	}
 }
 
+func WithRequester(requester Requester) func(exmo *Exmo) {
+	return func(e *Exmo) {
+		e.requester = requester
+	}
+}
+
+func WithCandleStore(store *CandleStore) func(exmo *Exmo) {
+	return func(e *Exmo) {
+		e.store = store
+	}
+}
+
+// WithGapFilling makes GetCandlesHistory fill the missing candles of illiquid pairs with policy.
+func WithGapFilling(policy FillPolicy) func(exmo *Exmo) {
+	return func(e *Exmo) {
+		e.fillGaps = true
+		e.fill = policy
+	}
+}
+
 func WithURL(url string) func(exmo *Exmo) {
 	return func(e *Exmo) {
 		e.url = url
@@ -176,19 +227,68 @@ func (e *Exmo) GetCurrencies() (Currencies, error) {
 	return currenciesResp, nil
 }
 
+func (e *Exmo) GetPairSettings() (PairSettings, error) {
+	data, err := e.requester.GetRequest("POST", e.url+pairSettings, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Exmo_GetPairSettings -> %w", err)
+	}
+
+	pairSettingsResp := PairSettings{}
+	err = json.Unmarshal(data, &pairSettingsResp)
+	if err != nil {
+		return nil, fmt.Errorf("Exmo_GetPairSettings -> %w", err)
+	}
+	return pairSettingsResp, nil
+}
+
 func (e *Exmo) GetCandlesHistory(pair string, limit int, start, end time.Time) (CandlesHistory, error) {
+	candlesHistoryResp, err := e.loadCandlesHistory(pair, limit, start, end)
+	if err != nil {
+		return CandlesHistory{}, fmt.Errorf("Exmo_GetCandlesHistory -> %w", err)
+	}
+
+	if e.fillGaps {
+		// Candles that have not opened yet are not missing.
+		if now := time.Now(); end.After(now) {
+			end = now
+		}
+		candlesHistoryResp.Candles, candlesHistoryResp.Synthesized = FillGaps(candlesHistoryResp.Candles, time.Duration(limit)*time.Minute, start, end, e.fill)
+	}
+	return candlesHistoryResp, nil
+}
+
+func (e *Exmo) loadCandlesHistory(pair string, limit int, start, end time.Time) (CandlesHistory, error) {
+	if e.store == nil {
+		return e.fetchCandlesHistory(pair, limit, start, end)
+	}
+
+	candles, err := e.store.Candles(e.url, pair, limit, start, end, func(start, end time.Time) ([]Candle, error) {
+		candlesHistoryResp, err := e.fetchCandlesHistory(pair, limit, start, end)
+		return candlesHistoryResp.Candles, err
+	})
+	if err != nil {
+		return CandlesHistory{}, err
+	}
+	return CandlesHistory{Candles: candles}, nil
+}
+
+func (e *Exmo) fetchCandlesHistory(pair string, limit int, start, end time.Time) (CandlesHistory, error) {
 	limitStr := strconv.Itoa(limit)
 	startStr, endStr := strconv.Itoa(int(start.Unix())), strconv.Itoa(int(end.Unix()))
 
 	data, err := e.requester.GetRequest("GET", e.url+candlesHistory+"?symbol="+pair+"&resolution="+limitStr+"&from="+startStr+"&to="+endStr, nil)
 	if err != nil {
-		return CandlesHistory{}, fmt.Errorf("Exmo_GetCandlesHistory -> %w", err)
+		return CandlesHistory{}, fmt.Errorf("Exmo_fetchCandlesHistory -> %w", err)
+	}
+	// An error body has no candles, which would otherwise pass for an empty range.
+	if err := apiError(data); err != nil {
+		return CandlesHistory{}, fmt.Errorf("Exmo_fetchCandlesHistory -> %w", err)
 	}
 
 	candlesHistoryResp := CandlesHistory{}
 	err = json.Unmarshal(data, &candlesHistoryResp)
 	if err != nil {
-		return CandlesHistory{}, fmt.Errorf("Exmo_GetCandlesHistory -> %w", err)
+		return CandlesHistory{}, fmt.Errorf("Exmo_fetchCandlesHistory -> %w", err)
 	}
 	return candlesHistoryResp, nil
 }
@@ -23,6 +23,23 @@ func TestWithClient(t *testing.T) {
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("unexpected result: got %v, got %v", *result, *expected)
 	}
+	if requester, ok := result.requester.(*Client); !ok || requester.client != client {
+		t.Errorf("unexpected requester: got %v, want client %p", result.requester, client)
+	}
+}
+
+func TestWithRequester(t *testing.T) {
+	requester := &MockClient{}
+	expected := &Exmo{client: &http.Client{}, url: "https://api.exmo.com/v1.1", isTest: false, requester: requester}
+	result := NewExmo(WithRequester(requester))
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected result: got %v, got %v", *result, *expected)
+	}
+
+	result = NewExmo(Test(), WithRequester(requester))
+	if result.requester != requester {
+		t.Errorf("unexpected requester: got %v, want %v", result.requester, requester)
+	}
 }
 
 func TestWithURL(t *testing.T) {
@@ -131,6 +148,19 @@ func TestExmo_GetCurrencies(t *testing.T) {
 	}
 }
 
+func TestExmo_GetPairSettings(t *testing.T) {
+	exmo := NewExmo(Test())
+	expectedSettings := PairSettings{"ADA_BTC": PairSetting{}, "ADA_USD": PairSetting{}}
+
+	result, err := exmo.GetPairSettings()
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result, expectedSettings) {
+		t.Errorf("unexpected result, got %v, want %v", result, expectedSettings)
+	}
+}
+
 func TestExmo_GetCandlesHistory(t *testing.T) {
 	exmo := NewExmo(Test())
 	pair := "ADA_BTC"
@@ -0,0 +1,760 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var ErrExpression = errors.New("invalid expression")
+
+// ExpressionError points at the position of the expression source that caused the error.
+type ExpressionError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos+1, e.Msg)
+}
+
+func (e *ExpressionError) Unwrap() error {
+	return ErrExpression
+}
+
+// ExpressionResult is a numeric or a boolean series with one value per candle.
+type ExpressionResult struct {
+	Numbers []float64
+	Bools   []bool
+	IsBool  bool
+}
+
+// Expression is a parsed signal expression like
+//
+//	crossover(ema(close, 12), ema(close, 26)) and rsi(close, 14) < 30
+//
+// It supports numbers, the price sources open, high, low, close, volume, hl2, hlc3, ohlc4 and
+// typical, arithmetic, comparisons, and, or, not, and function calls. The moving averages and
+// rsi take a series and a period, crossover and crossunder take two series, abs, min, max and
+// prev work on series too. Any other function is looked up in the indicator registry and called
+// with constant arguments on the candles, like atr(14); the line of a multi-line indicator is
+// selected with a dot, like macd(12, 26, 9).signal.
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+func ParseExpression(source string) (*Expression, error) {
+	tokens, err := lexExpression(source)
+	if err != nil {
+		return nil, fmt.Errorf("ParseExpression -> %w", err)
+	}
+
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("ParseExpression -> %w", err)
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("ParseExpression -> %w", &ExpressionError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)})
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Evaluate evaluates the expression over candles with the averages, the price source and
+// the registry of indicator, or of an indicator with the defaults if it is nil.
+func (e *Expression) Evaluate(candles []Candle, indicator *Indicator) (ExpressionResult, error) {
+	if indicator == nil {
+		indicator = NewIndicator(nil)
+	}
+	ctx := &exprContext{candles: candles, indicator: indicator}
+	value, err := e.root.eval(ctx)
+	if err != nil {
+		return ExpressionResult{}, fmt.Errorf("Expression_Evaluate -> %w", err)
+	}
+	if value.isBool {
+		return ExpressionResult{Bools: value.bools, IsBool: true}, nil
+	}
+	return ExpressionResult{Numbers: value.numbers}, nil
+}
+
+// EvaluateExpression parses expression and evaluates it over the candles of pair.
+func (i *Indicator) EvaluateExpression(expression string, pair string, limit int, from, to time.Time) (ExpressionResult, error) {
+	parsed, err := ParseExpression(expression)
+	if err != nil {
+		return ExpressionResult{}, fmt.Errorf("Indicator_EvaluateExpression -> %w", err)
+	}
+
+	candles, err := i.GetCandles(pair, limit, from, to)
+	if err != nil {
+		return ExpressionResult{}, fmt.Errorf("Indicator_EvaluateExpression -> %w", err)
+	}
+
+	result, err := parsed.Evaluate(candles, i)
+	if err != nil {
+		return ExpressionResult{}, fmt.Errorf("Indicator_EvaluateExpression -> %w", err)
+	}
+	return result, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+	tokDot
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lexExpression(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: strings.ToLower(string(runes[start:i])), pos: start})
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", pos: i})
+			i++
+		case r == '.':
+			tokens = append(tokens, token{kind: tokDot, text: ".", pos: i})
+			i++
+		case strings.ContainsRune("<>=!", r) && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, token{kind: tokOp, text: string(runes[i : i+2]), pos: i})
+			i += 2
+		case strings.ContainsRune("+-*/<>", r):
+			tokens = append(tokens, token{kind: tokOp, text: string(r), pos: i})
+			i++
+		default:
+			return nil, &ExpressionError{Pos: i, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "end of expression", pos: len(runes)}), nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) expect(kind tokenKind, text string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, &ExpressionError{Pos: tok.pos, Msg: fmt.Sprintf("expected %s, got %q", text, tok.text)}
+	}
+	return tok, nil
+}
+
+func (p *exprParser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && tok.text == keyword
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		tok := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "or", left: left, right: right, pos: tok.pos}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		tok := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "and", left: left, right: right, pos: tok.pos}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if p.isKeyword("not") {
+		tok := p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "not", operand: operand, pos: tok.pos}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind == tokOp && strings.ContainsAny(tok.text, "<>=!") {
+		p.next()
+		right, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: tok.text, left: left, right: right, pos: tok.pos}, nil
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseSum() (exprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokOp && (tok.text == "+" || tok.text == "-"); tok = p.peek() {
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: tok.text, left: left, right: right, pos: tok.pos}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseProduct() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokOp && (tok.text == "*" || tok.text == "/"); tok = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: tok.text, left: left, right: right, pos: tok.pos}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == "-" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: "-", operand: operand, pos: tok.pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, &ExpressionError{Pos: tok.pos, Msg: fmt.Sprintf("invalid number %q", tok.text)}
+		}
+		return &numberNode{value: value}, nil
+
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "\")\""); err != nil {
+			return nil, err
+		}
+		return node, nil
+
+	case tokIdent:
+		if tok.text == "and" || tok.text == "or" || tok.text == "not" {
+			return nil, &ExpressionError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+		}
+		if p.peek().kind != tokLParen {
+			return &identNode{name: tok.text, pos: tok.pos}, nil
+		}
+		return p.parseCall(tok)
+	}
+	return nil, &ExpressionError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+}
+
+func (p *exprParser) parseCall(name token) (exprNode, error) {
+	p.next()
+	call := &callNode{name: name.text, pos: name.pos}
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if _, err := p.expect(tokRParen, "\")\""); err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind == tokDot {
+		p.next()
+		line, err := p.expect(tokIdent, "output line")
+		if err != nil {
+			return nil, err
+		}
+		call.line = line.text
+	}
+	return call, nil
+}
+
+type exprContext struct {
+	candles   []Candle
+	indicator *Indicator
+}
+
+// exprValue is a series with one value per candle. A constant keeps its value too, so that it
+// is known without candles.
+type exprValue struct {
+	numbers  []float64
+	bools    []bool
+	isBool   bool
+	constant bool
+	value    float64
+}
+
+type exprNode interface {
+	eval(ctx *exprContext) (exprValue, error)
+}
+
+type numberNode struct {
+	value float64
+}
+
+func (n *numberNode) eval(ctx *exprContext) (exprValue, error) {
+	numbers := make([]float64, len(ctx.candles))
+	for i := range numbers {
+		numbers[i] = n.value
+	}
+	return exprValue{numbers: numbers, constant: true, value: n.value}, nil
+}
+
+var expressionSources = map[string]PriceSource{
+	"open":    SourceOpen,
+	"high":    SourceHigh,
+	"low":     SourceLow,
+	"close":   SourceClose,
+	"hl2":     SourceHL2,
+	"hlc3":    SourceHLC3,
+	"ohlc4":   SourceOHLC4,
+	"typical": SourceTypical,
+}
+
+type identNode struct {
+	name string
+	pos  int
+}
+
+func (n *identNode) eval(ctx *exprContext) (exprValue, error) {
+	if n.name == "volume" {
+		numbers := make([]float64, 0, len(ctx.candles))
+		for _, candle := range ctx.candles {
+			numbers = append(numbers, candle.V)
+		}
+		return exprValue{numbers: numbers}, nil
+	}
+
+	source, ok := expressionSources[n.name]
+	if !ok {
+		return exprValue{}, &ExpressionError{Pos: n.pos, Msg: fmt.Sprintf("unknown series %q", n.name)}
+	}
+	return exprValue{numbers: Prices(ctx.candles, source)}, nil
+}
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+	pos     int
+}
+
+func (n *unaryNode) eval(ctx *exprContext) (exprValue, error) {
+	operand, err := n.operand.eval(ctx)
+	if err != nil {
+		return exprValue{}, err
+	}
+
+	if n.op == "not" {
+		if !operand.isBool {
+			return exprValue{}, &ExpressionError{Pos: n.pos, Msg: "not needs a boolean operand"}
+		}
+		bools := make([]bool, len(operand.bools))
+		for i, b := range operand.bools {
+			bools[i] = !b
+		}
+		return exprValue{bools: bools, isBool: true}, nil
+	}
+
+	if operand.isBool {
+		return exprValue{}, &ExpressionError{Pos: n.pos, Msg: "unary minus needs a numeric operand"}
+	}
+	numbers := make([]float64, len(operand.numbers))
+	for i, number := range operand.numbers {
+		numbers[i] = -number
+	}
+	return exprValue{numbers: numbers, constant: operand.constant, value: -operand.value}, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+	pos         int
+}
+
+func (n *binaryNode) eval(ctx *exprContext) (exprValue, error) {
+	left, err := n.left.eval(ctx)
+	if err != nil {
+		return exprValue{}, err
+	}
+	right, err := n.right.eval(ctx)
+	if err != nil {
+		return exprValue{}, err
+	}
+
+	if n.op == "and" || n.op == "or" {
+		if !left.isBool || !right.isBool {
+			return exprValue{}, &ExpressionError{Pos: n.pos, Msg: n.op + " needs boolean operands"}
+		}
+		bools := make([]bool, len(left.bools))
+		for i := range bools {
+			if n.op == "and" {
+				bools[i] = left.bools[i] && right.bools[i]
+			} else {
+				bools[i] = left.bools[i] || right.bools[i]
+			}
+		}
+		return exprValue{bools: bools, isBool: true}, nil
+	}
+
+	if left.isBool || right.isBool {
+		return exprValue{}, &ExpressionError{Pos: n.pos, Msg: fmt.Sprintf("%s needs numeric operands", n.op)}
+	}
+
+	switch n.op {
+	case "+", "-", "*", "/":
+		apply := func(a, b float64) float64 {
+			switch n.op {
+			case "+":
+				return a + b
+			case "-":
+				return a - b
+			case "*":
+				return a * b
+			}
+			return a / b
+		}
+		numbers := make([]float64, len(left.numbers))
+		for i := range numbers {
+			numbers[i] = apply(left.numbers[i], right.numbers[i])
+		}
+		return exprValue{numbers: numbers, constant: left.constant && right.constant, value: apply(left.value, right.value)}, nil
+	}
+
+	bools := make([]bool, len(left.numbers))
+	for i := range bools {
+		a, b := left.numbers[i], right.numbers[i]
+		switch n.op {
+		case "<":
+			bools[i] = a < b
+		case ">":
+			bools[i] = a > b
+		case "<=":
+			bools[i] = a <= b
+		case ">=":
+			bools[i] = a >= b
+		case "==":
+			bools[i] = a == b
+		case "!=":
+			bools[i] = a != b && !math.IsNaN(a) && !math.IsNaN(b)
+		default:
+			return exprValue{}, &ExpressionError{Pos: n.pos, Msg: fmt.Sprintf("unknown operator %q", n.op)}
+		}
+	}
+	return exprValue{bools: bools, isBool: true}, nil
+}
+
+type callNode struct {
+	name string
+	args []exprNode
+	line string
+	pos  int
+}
+
+func (n *callNode) eval(ctx *exprContext) (exprValue, error) {
+	args := make([]exprValue, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := arg.eval(ctx)
+		if err != nil {
+			return exprValue{}, err
+		}
+		args = append(args, value)
+	}
+
+	switch n.name {
+	case "crossover", "crossunder", "abs", "min", "max", "prev":
+		if n.line != "" {
+			return exprValue{}, n.errorf("%s has no output lines", n.name)
+		}
+	}
+
+	switch n.name {
+	case "crossover", "crossunder":
+		return n.cross(args)
+	case "abs":
+		return n.mapNumbers(args, 1, func(values ...float64) float64 { return math.Abs(values[0]) })
+	case "min":
+		return n.mapNumbers(args, 2, func(values ...float64) float64 { return math.Min(values[0], values[1]) })
+	case "max":
+		return n.mapNumbers(args, 2, func(values ...float64) float64 { return math.Max(values[0], values[1]) })
+	case "prev":
+		return n.prev(args)
+	}
+
+	if len(args) > 0 && !args[0].constant {
+		if n.line != "" {
+			return exprValue{}, n.errorf("%s has no output lines", n.name)
+		}
+		return n.seriesFunction(ctx, args)
+	}
+	return n.indicator(ctx, args)
+}
+
+func isSeriesFunction(name string) bool {
+	_, ok := movingAverages[name]
+	return ok || name == "rsi"
+}
+
+func (n *callNode) errorf(format string, args ...interface{}) error {
+	return &ExpressionError{Pos: n.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (n *callNode) numericArgs(args []exprValue, count int) error {
+	if len(args) != count {
+		return n.errorf("%s takes %d arguments, got %d", n.name, count, len(args))
+	}
+	for _, arg := range args {
+		if arg.isBool {
+			return n.errorf("%s needs numeric arguments", n.name)
+		}
+	}
+	return nil
+}
+
+func (n *callNode) cross(args []exprValue) (exprValue, error) {
+	if err := n.numericArgs(args, 2); err != nil {
+		return exprValue{}, err
+	}
+
+	a, b := args[0].numbers, args[1].numbers
+	bools := make([]bool, len(a))
+	for i := 1; i < len(a); i++ {
+		if n.name == "crossover" {
+			bools[i] = a[i-1] <= b[i-1] && a[i] > b[i]
+		} else {
+			bools[i] = a[i-1] >= b[i-1] && a[i] < b[i]
+		}
+	}
+	return exprValue{bools: bools, isBool: true}, nil
+}
+
+func (n *callNode) mapNumbers(args []exprValue, count int, f func(values ...float64) float64) (exprValue, error) {
+	if err := n.numericArgs(args, count); err != nil {
+		return exprValue{}, err
+	}
+
+	numbers := make([]float64, len(args[0].numbers))
+	values := make([]float64, count)
+	constant := true
+	for j, arg := range args {
+		constant = constant && arg.constant
+		values[j] = arg.value
+	}
+	value := f(values...)
+	for i := range numbers {
+		for j, arg := range args {
+			values[j] = arg.numbers[i]
+		}
+		numbers[i] = f(values...)
+	}
+	return exprValue{numbers: numbers, constant: constant, value: value}, nil
+}
+
+func (n *callNode) prev(args []exprValue) (exprValue, error) {
+	offset := 1
+	if len(args) == 2 {
+		period, err := n.period(args[1])
+		if err != nil {
+			return exprValue{}, err
+		}
+		offset = period
+		args = args[:1]
+	}
+	if err := n.numericArgs(args, 1); err != nil {
+		return exprValue{}, err
+	}
+
+	numbers := nanSeries(len(args[0].numbers))
+	for i := offset; i < len(numbers); i++ {
+		numbers[i] = args[0].numbers[i-offset]
+	}
+	return exprValue{numbers: numbers}, nil
+}
+
+func (n *callNode) period(arg exprValue) (int, error) {
+	if arg.isBool || !arg.constant {
+		return 0, n.errorf("%s needs a constant period", n.name)
+	}
+	period := arg.value
+	if period <= 0 || period != math.Trunc(period) {
+		return 0, n.errorf("%s needs a positive integer period, got %v", n.name, period)
+	}
+	return int(period), nil
+}
+
+// seriesFunction applies a moving average or rsi of the registry of the indicator to the series
+// of the first argument, so that sma and ema are the ones set by WithSMA and WithEMA.
+func (n *callNode) seriesFunction(ctx *exprContext, args []exprValue) (exprValue, error) {
+	if !isSeriesFunction(n.name) {
+		return exprValue{}, n.errorf("%s does not take a series", n.name)
+	}
+	if err := n.numericArgs(args, 2); err != nil {
+		return exprValue{}, err
+	}
+	period, err := n.period(args[1])
+	if err != nil {
+		return exprValue{}, err
+	}
+
+	candles := make([]Candle, 0, len(args[0].numbers))
+	for _, number := range args[0].numbers {
+		candles = append(candles, Candle{O: number, H: number, L: number, C: number})
+	}
+	output, err := ctx.indicator.registry.Calculate(n.name, candles, SourceClose, float64(period))
+	if err != nil {
+		return exprValue{}, n.errorf("%v", err)
+	}
+	return exprValue{numbers: output[ValueLine]}, nil
+}
+
+// indicator calculates a registered indicator over the candles with constant arguments and
+// the price source of the indicator.
+func (n *callNode) indicator(ctx *exprContext, args []exprValue) (exprValue, error) {
+	definition, err := ctx.indicator.registry.Lookup(n.name)
+	if err != nil {
+		return exprValue{}, n.errorf("unknown function %q", n.name)
+	}
+
+	values := make([]float64, 0, len(args))
+	for _, arg := range args {
+		if arg.isBool || !arg.constant {
+			return exprValue{}, n.errorf("%s needs constant arguments", n.name)
+		}
+		values = append(values, arg.value)
+	}
+	values, err = definition.args(values)
+	if err != nil {
+		return exprValue{}, n.errorf("%v", err)
+	}
+
+	output, err := definition.Calculate(ctx.candles, ctx.indicator.source, values)
+	if err != nil {
+		return exprValue{}, n.errorf("%v", err)
+	}
+
+	line := n.line
+	if line == "" {
+		if _, ok := output[ValueLine]; !ok {
+			return exprValue{}, n.errorf("%s has several output lines, select one like %s(...).%s", n.name, n.name, anyLine(output))
+		}
+		line = ValueLine
+	}
+	numbers, ok := output[line]
+	if !ok {
+		return exprValue{}, n.errorf("%s has no output line %q", n.name, line)
+	}
+	return exprValue{numbers: numbers[:minInt(len(numbers), len(ctx.candles))]}, nil
+}
+
+func anyLine(output Output) string {
+	lines := make([]string, 0, len(output))
+	for line := range output {
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, ", ")
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func closeCandles(closes ...float64) []Candle {
+	res := make([]Candle, 0, len(closes))
+	for i, price := range closes {
+		res = append(res, Candle{T: int64(i) * 60000, O: price, H: price + 1, L: price - 1, C: price, V: 1})
+	}
+	return res
+}
+
+func evaluateExpression(t *testing.T, source string, candles []Candle) ExpressionResult {
+	t.Helper()
+	expression, err := ParseExpression(source)
+	if !assert.NoError(t, err) {
+		return ExpressionResult{}
+	}
+	result, err := expression.Evaluate(candles, nil)
+	assert.NoError(t, err)
+	return result
+}
+
+func TestParseExpression_errors(t *testing.T) {
+	type testData struct {
+		source string
+		pos    int
+	}
+
+	testCases := []testData{
+		{source: "", pos: 1},
+		{source: "close >", pos: 8},
+		{source: "sma(close, 3", pos: 13},
+		{source: "close $ 3", pos: 7},
+		{source: "close 3", pos: 7},
+		{source: "and close", pos: 1},
+		{source: "macd(12, 26, 9).", pos: 17},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseExpression(tc.source)
+
+		var exprErr *ExpressionError
+		if assert.True(t, errors.As(err, &exprErr), "%q: %v", tc.source, err) {
+			assert.Equal(t, tc.pos, exprErr.Pos+1, tc.source)
+		}
+		assert.ErrorIs(t, err, ErrExpression)
+	}
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	nan := math.NaN()
+	candles := closeCandles(1, 2, 3, 4, 5)
+
+	result := evaluateExpression(t, "(close + 1) * 2 - -high / 2", candles)
+	assert.False(t, result.IsBool)
+	assertSeries(t, []float64{5, 7.5, 10, 12.5, 15}, result.Numbers, 1e-9)
+
+	result = evaluateExpression(t, "sma(close, 2)", candles)
+	assertSeries(t, []float64{nan, 1.5, 2.5, 3.5, 4.5}, result.Numbers, 1e-9)
+
+	result = evaluateExpression(t, "prev(close, 2) + max(close, 3)", candles)
+	assertSeries(t, []float64{nan, nan, 4, 6, 8}, result.Numbers, 1e-9)
+
+	result = evaluateExpression(t, "close >= 2 and not close == 4 or close < 1.5", candles)
+	assert.True(t, result.IsBool)
+	assert.Equal(t, []bool{true, true, true, false, true}, result.Bools)
+
+	result = evaluateExpression(t, "sma(close, 3) > 2", candles)
+	assert.Equal(t, []bool{false, false, false, true, true}, result.Bools)
+}
+
+func TestExpression_Evaluate_crossover(t *testing.T) {
+	candles := closeCandles(5, 4, 3, 4, 6, 5, 3)
+
+	result := evaluateExpression(t, "crossover(close, sma(close, 2))", candles)
+	assert.Equal(t, []bool{false, false, false, true, false, false, false}, result.Bools)
+
+	result = evaluateExpression(t, "crossunder(close, sma(close, 2))", candles)
+	assert.Equal(t, []bool{false, false, false, false, false, true, false}, result.Bools)
+
+	result = evaluateExpression(t, "crossover(ema(close, 2), ema(close, 3)) and rsi(close, 2) < 80", candles)
+	assert.Len(t, result.Bools, len(candles))
+}
+
+func TestExpression_Evaluate_registry(t *testing.T) {
+	candles := referenceCandles()
+
+	result := evaluateExpression(t, "rsi(14)", candles)
+	assertSeries(t, calculateRSI(referencePrices, 14), result.Numbers, 1e-9)
+
+	result = evaluateExpression(t, "macd(3, 6, 2).signal", candles)
+	assertSeries(t, calculateMACD(referencePrices, 3, 6, 2).Signal, result.Numbers, 1e-9)
+
+	result = evaluateExpression(t, "atr(3) / close", candles)
+	atr := calculateATR(candles, 3)
+	for i := range atr {
+		atr[i] /= candles[i].C
+	}
+	assertSeries(t, atr, result.Numbers, 1e-9)
+}
+
+func TestExpression_Evaluate_errors(t *testing.T) {
+	testCases := []string{
+		"price > 1",
+		"unknown(3)",
+		"macd(12, 26, 9)",
+		"macd(12, 26, 9).value",
+		"sma(close, 2).upper",
+		"sma(close, close)",
+		"sma(close, 0)",
+		"rsi(close)",
+		"close and close > 1",
+		"not close",
+		"(close > 1) + 2",
+		"crossover(close)",
+		"atr(close)",
+	}
+	candles := closeCandles(1, 2, 3)
+
+	for _, tc := range testCases {
+		expression, err := ParseExpression(tc)
+		if !assert.NoError(t, err, tc) {
+			continue
+		}
+
+		_, err = expression.Evaluate(candles, nil)
+		assert.ErrorIs(t, err, ErrExpression, tc)
+	}
+}
+
+func TestIndicator_EvaluateExpression(t *testing.T) {
+	exchange := candlesExchange(`[{"t":0,"o":1,"c":1,"h":2,"l":0,"v":1},{"t":60000,"o":1,"c":3,"h":3,"l":1,"v":1},{"t":120000,"o":3,"c":2,"h":3,"l":2,"v":1}]`)
+	indicator := NewIndicator(exchange)
+
+	result, err := indicator.EvaluateExpression("close > open", "BTC_USD", 1, time.Unix(0, 0), time.Unix(180, 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, true, false}, result.Bools)
+
+	_, err = indicator.EvaluateExpression("close >", "BTC_USD", 1, time.Unix(0, 0), time.Unix(180, 0))
+	assert.ErrorIs(t, err, ErrExpression)
+}
+
+func TestExpression_Evaluate_indicator(t *testing.T) {
+	candles := closeCandles(1, 2, 3)
+	wma, err := MovingAverageByName("wma")
+	assert.NoError(t, err)
+	indicator := NewIndicator(nil, WithSMA(wma), WithPriceSource(SourceHigh))
+
+	expression, err := ParseExpression("sma(close, 2)")
+	assert.NoError(t, err)
+	result, err := expression.Evaluate(candles, indicator)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 5.0 / 3, 8.0 / 3}, result.Numbers, 1e-9)
+
+	expression, err = ParseExpression("ema(2) - ema(close, 2)")
+	assert.NoError(t, err)
+	result, err = expression.Evaluate(candles, indicator)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 1, 1}, result.Numbers, 1e-9)
+
+	for _, source := range []string{"sma(close, 2 + 1) > 1", "rsi(14) < 30", "prev(close, 2)"} {
+		expression, err = ParseExpression(source)
+		assert.NoError(t, err)
+		result, err = expression.Evaluate(nil, indicator)
+		assert.NoError(t, err, source)
+		assert.Empty(t, result.Numbers)
+		assert.Empty(t, result.Bools)
+	}
+}
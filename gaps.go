@@ -0,0 +1,137 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+type FillPolicy int
+
+const (
+	// FillForward repeats the previous close as open, high, low and close with zero volume.
+	FillForward FillPolicy = iota
+	// FillInterpolate interpolates the close linearly between the neighbouring candles, with zero volume.
+	FillInterpolate
+	// FillNaN leaves the prices of missing candles as NaN, with zero volume.
+	FillNaN
+)
+
+// Gap describes consecutive missing candles. From and To are the timestamps of the first
+// and the last missing candle in milliseconds, like Candle.T.
+type Gap struct {
+	From  int64
+	To    int64
+	Count int
+}
+
+// DetectGaps returns the intervals of the resolution grid between from and to that have no candle.
+// The grid goes through the timestamps of the candles, or starts at from if there are none.
+// A zero from or to bounds the grid at the first or the last candle instead.
+func DetectGaps(candles []Candle, resolution time.Duration, from, to time.Time) []Gap {
+	step := resolution.Milliseconds()
+	sorted := append([]Candle(nil), candles...)
+	sortCandles(sorted)
+	first, last, ok := gridBounds(sorted, step, from, to)
+	if !ok {
+		return nil
+	}
+
+	var gaps []Gap
+	expected := first
+	for _, candle := range sorted {
+		if candle.T > expected {
+			count := int((candle.T - expected + step - 1) / step)
+			gaps = append(gaps, Gap{From: expected, To: expected + int64(count-1)*step, Count: count})
+		}
+		expected = candle.T + step
+	}
+	if expected <= last {
+		count := int((last-expected)/step) + 1
+		gaps = append(gaps, Gap{From: expected, To: last, Count: count})
+	}
+	return gaps
+}
+
+// FillGaps returns the candles between from and to with every gap filled according to policy
+// and the timestamps of the synthesized candles. The missing candles before the first candle
+// and after the last one have no neighbour to interpolate with, so they repeat the open of
+// the first candle and the close of the last one, or are NaN. Without any candle there is nothing
+// to fill with and no candles are returned.
+func FillGaps(candles []Candle, resolution time.Duration, from, to time.Time, policy FillPolicy) ([]Candle, []int64) {
+	step := resolution.Milliseconds()
+	sorted := append([]Candle(nil), candles...)
+	sortCandles(sorted)
+	first, last, ok := gridBounds(sorted, step, from, to)
+	if !ok || len(sorted) == 0 {
+		return sorted, nil
+	}
+
+	res := make([]Candle, 0, len(sorted))
+	var synthesized []int64
+	for ts := first; ts < sorted[0].T; ts += step {
+		res = append(res, edgeCandle(sorted[0].O, ts, policy))
+		synthesized = append(synthesized, ts)
+	}
+	res = append(res, sorted[0])
+	for i := 1; i < len(sorted); i++ {
+		prev, next := sorted[i-1], sorted[i]
+		for ts := prev.T + step; ts < next.T; ts += step {
+			res = append(res, fillCandle(prev, next, ts, policy))
+			synthesized = append(synthesized, ts)
+		}
+		res = append(res, next)
+	}
+	for ts := sorted[len(sorted)-1].T + step; ts <= last; ts += step {
+		res = append(res, edgeCandle(sorted[len(sorted)-1].C, ts, policy))
+		synthesized = append(synthesized, ts)
+	}
+	return res, synthesized
+}
+
+// gridBounds returns the first and the last point of the grid of step between from and to
+// that goes through the first of the sorted candles, or is aligned to step without candles.
+func gridBounds(sorted []Candle, step int64, from, to time.Time) (int64, int64, bool) {
+	if step <= 0 {
+		return 0, 0, false
+	}
+
+	var first, last int64
+	switch {
+	case len(sorted) > 0:
+		first, last = sorted[0].T, sorted[len(sorted)-1].T
+	case !from.IsZero() && !to.IsZero():
+		first = (from.UnixMilli() + step - 1) / step * step
+		last = first - step
+	default:
+		return 0, 0, false
+	}
+
+	if !from.IsZero() && from.UnixMilli() < first {
+		first -= (first - from.UnixMilli()) / step * step
+	}
+	if !to.IsZero() && to.UnixMilli() > last {
+		last += (to.UnixMilli() - last) / step * step
+	}
+	return first, last, first <= last
+}
+
+func fillCandle(prev, next Candle, ts int64, policy FillPolicy) Candle {
+	var price float64
+	switch policy {
+	case FillInterpolate:
+		share := float64(ts-prev.T) / float64(next.T-prev.T)
+		price = prev.C + (next.C-prev.C)*share
+	case FillNaN:
+		price = math.NaN()
+	default:
+		price = prev.C
+	}
+	return Candle{T: ts, O: price, C: price, H: price, L: price}
+}
+
+func edgeCandle(price float64, ts int64, policy FillPolicy) Candle {
+	if policy == FillNaN {
+		price = math.NaN()
+	}
+	return Candle{T: ts, O: price, C: price, H: price, L: price}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectGaps(t *testing.T) {
+	candles := []Candle{{T: 600000}, {T: 0}, {T: 60000}, {T: 240000}, {T: 300000}}
+
+	type testData struct {
+		candles  []Candle
+		from, to time.Time
+		expected []Gap
+	}
+
+	testCases := []testData{
+		{candles: candles, expected: []Gap{{From: 120000, To: 180000, Count: 2}, {From: 360000, To: 540000, Count: 4}}},
+		{candles: candles[1:3]},
+		{candles: candles[1:3], from: time.Unix(0, 0), to: time.Unix(60, 0)},
+		{candles: candles[3:], from: time.Unix(90, 0), to: time.Unix(719, 0), expected: []Gap{{From: 120000, To: 180000, Count: 2}, {From: 360000, To: 660000, Count: 6}}},
+		{from: time.Unix(30, 0), to: time.Unix(200, 0), expected: []Gap{{From: 60000, To: 180000, Count: 3}}},
+		{from: time.Unix(30, 0), to: time.Unix(50, 0)},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, DetectGaps(tc.candles, time.Minute, tc.from, tc.to))
+	}
+	assert.Nil(t, DetectGaps(candles, 0, time.Time{}, time.Time{}))
+}
+
+func TestFillGaps(t *testing.T) {
+	candles := []Candle{{T: 0, C: 1, V: 5}, {T: 180000, C: 4, V: 2}}
+
+	type testData struct {
+		policy   FillPolicy
+		expected []float64
+	}
+
+	testCases := []testData{
+		{policy: FillForward, expected: []float64{1, 1, 1, 4}},
+		{policy: FillInterpolate, expected: []float64{1, 2, 3, 4}},
+		{policy: FillNaN, expected: []float64{1, math.NaN(), math.NaN(), 4}},
+	}
+
+	for _, tc := range testCases {
+		result, synthesized := FillGaps(candles, time.Minute, time.Time{}, time.Time{}, tc.policy)
+		assert.Equal(t, []int64{60000, 120000}, synthesized)
+		assert.Len(t, result, len(tc.expected))
+		for i, candle := range result {
+			assert.Equal(t, int64(i)*60000, candle.T)
+			if math.IsNaN(tc.expected[i]) {
+				assert.True(t, math.IsNaN(candle.C))
+			} else {
+				assert.Equal(t, tc.expected[i], candle.C)
+			}
+		}
+		assert.Equal(t, float64(0), result[1].V)
+	}
+
+	result, synthesized := FillGaps(candles, time.Minute, time.Unix(-90, 0), time.Unix(300, 0), FillForward)
+	assert.Equal(t, []int64{-60000, 60000, 120000, 240000, 300000}, synthesized)
+	assert.Equal(t, []float64{0, 1, 1, 1, 4, 4, 4}, Prices(result, SourceClose))
+
+	result, synthesized = FillGaps(candles[:1], time.Minute, time.Unix(0, 0), time.Unix(60, 0), FillNaN)
+	assert.Equal(t, []int64{60000}, synthesized)
+	assert.Len(t, result, 2)
+	assert.True(t, math.IsNaN(result[1].C))
+
+	result, synthesized = FillGaps(nil, time.Minute, time.Unix(0, 0), time.Unix(300, 0), FillForward)
+	assert.Empty(t, result)
+	assert.Nil(t, synthesized)
+}
+
+func TestWithGapFilling(t *testing.T) {
+	requester := RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		return []byte(`{"candles":[{"t":1701289800000,"c":1},{"t":1701295200000,"c":4}]}`), nil
+	})
+	exmo := NewExmo(WithRequester(requester), WithGapFilling(FillInterpolate))
+
+	result, err := exmo.GetClosePrice("ADA_BTC", 30, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 3, 4, 4, 4}, result)
+
+	candlesHistory, err := exmo.GetCandlesHistory("ADA_BTC", 30, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1701291600000, 1701293400000, 1701297000000, 1701298800000}, candlesHistory.Synthesized)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrInvalidPeriod = errors.New("period must be positive")
+
+type PriceSource int
+
+const (
+	SourceClose PriceSource = iota
+	SourceOpen
+	SourceHigh
+	SourceLow
+	// SourceHL2 is (high + low) / 2.
+	SourceHL2
+	// SourceHLC3 is (high + low + close) / 3.
+	SourceHLC3
+	// SourceOHLC4 is (open + high + low + close) / 4.
+	SourceOHLC4
+)
+
+// SourceTypical is the typical price, which is the same as HLC3.
+const SourceTypical = SourceHLC3
+
+func (s PriceSource) Price(c Candle) float64 {
+	switch s {
+	case SourceOpen:
+		return c.O
+	case SourceHigh:
+		return c.H
+	case SourceLow:
+		return c.L
+	case SourceHL2:
+		return (c.H + c.L) / 2
+	case SourceHLC3:
+		return (c.H + c.L + c.C) / 3
+	case SourceOHLC4:
+		return (c.O + c.H + c.L + c.C) / 4
+	}
+	return c.C
+}
+
+func Prices(candles []Candle, source PriceSource) []float64 {
+	res := make([]float64, 0, len(candles))
+	for _, candle := range candles {
+		res = append(res, source.Price(candle))
+	}
+	return res
+}
+
+type CandleIndicatorer interface {
+	SMACandles(candles []Candle, period int, source PriceSource) ([]float64, error)
+	EMACandles(candles []Candle, period int, source PriceSource) ([]float64, error)
+}
+
+func WithPriceSource(source PriceSource) IndicatorOption {
+	return func(i *Indicator) {
+		i.source = source
+	}
+}
+
+// GetCandles fetches the candles of pair the candle-based indicators are computed from,
+// converted by the chart transform set by WithChart.
+func (i *Indicator) GetCandles(pair string, resolution int, from, to time.Time) ([]Candle, error) {
+	candlesHistory, err := i.exchange.GetCandlesHistory(pair, resolution, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_GetCandles -> %w", err)
+	}
+	if i.chart == nil {
+		return candlesHistory.Candles, nil
+	}
+
+	candles, err := i.chart(candlesHistory.Candles)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_GetCandles -> %w", err)
+	}
+	return candles, nil
+}
+
+// SMACandles is the rolling simple average of the source prices of candles, NaN until period candles.
+func (i *Indicator) SMACandles(candles []Candle, period int, source PriceSource) ([]float64, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_SMACandles -> %w", ErrInvalidPeriod)
+	}
+	output, err := i.registry.Calculate("sma", candles, source, float64(period))
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_SMACandles -> %w", err)
+	}
+	return output[ValueLine], nil
+}
+
+// EMACandles is the exponential average of the source prices of candles, seeded with the simple
+// average of the first period candles.
+func (i *Indicator) EMACandles(candles []Candle, period int, source PriceSource) ([]float64, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_EMACandles -> %w", ErrInvalidPeriod)
+	}
+	output, err := i.registry.Calculate("ema", candles, source, float64(period))
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_EMACandles -> %w", err)
+	}
+	return output[ValueLine], nil
+}
+
+var ErrInvalidParams = errors.New("invalid indicator parameters")
+
+// The helpers below follow the convention of the candle-based indicators: the result has
+// the same length as the input and holds NaN while there is not enough data for a value.
+
+func nanSeries(n int) []float64 {
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = math.NaN()
+	}
+	return res
+}
+
+// firstValid returns the index of the first value that starts a run of period non-NaN values, or -1.
+func firstValid(data []float64, period int) int {
+	run := 0
+	for i, value := range data {
+		if math.IsNaN(value) {
+			run = 0
+			continue
+		}
+		run++
+		if run == period {
+			return i - period + 1
+		}
+	}
+	return -1
+}
+
+func simpleAverage(data []float64, period int) []float64 {
+	res := nanSeries(len(data))
+	var sum float64
+	valid := 0
+	for i, value := range data {
+		if math.IsNaN(value) {
+			sum, valid = 0, 0
+			continue
+		}
+		sum += value
+		valid++
+		if valid > period {
+			sum -= data[i-period]
+			valid = period
+		}
+		if valid == period {
+			res[i] = sum / float64(period)
+		}
+	}
+	return res
+}
+
+// smoothedAverage seeds with the simple average of the first period values and then applies
+// value*alpha + previous*(1-alpha). Leading NaN values are skipped.
+func smoothedAverage(data []float64, period int, alpha float64) []float64 {
+	res := nanSeries(len(data))
+	start := firstValid(data, period)
+	if start < 0 {
+		return res
+	}
+
+	var sum float64
+	for _, value := range data[start : start+period] {
+		sum += value
+	}
+	prev := sum / float64(period)
+	res[start+period-1] = prev
+	for i := start + period; i < len(data); i++ {
+		prev = data[i]*alpha + prev*(1-alpha)
+		res[i] = prev
+	}
+	return res
+}
+
+func exponentialAverage(data []float64, period int) []float64 {
+	return smoothedAverage(data, period, 2/float64(period+1))
+}
+
+func wilderAverage(data []float64, period int) []float64 {
+	return smoothedAverage(data, period, 1/float64(period))
+}
+
+func highest(candles []Candle, end, period int) float64 {
+	res := candles[end].H
+	for _, candle := range candles[end-period+1 : end] {
+		res = math.Max(res, candle.H)
+	}
+	return res
+}
+
+func lowest(candles []Candle, end, period int) float64 {
+	res := candles[end].L
+	for _, candle := range candles[end-period+1 : end] {
+		res = math.Min(res, candle.L)
+	}
+	return res
+}
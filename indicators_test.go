@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testCandles = []Candle{
+	{T: 0, O: 1, H: 4, L: 1, C: 3, V: 10},
+	{T: 60000, O: 3, H: 6, L: 2, C: 5, V: 20},
+	{T: 120000, O: 5, H: 8, L: 4, C: 4, V: 30},
+}
+
+func TestPriceSource_Price(t *testing.T) {
+	candle := Candle{O: 1, H: 4, L: 1, C: 3}
+
+	type testData struct {
+		source   PriceSource
+		expected float64
+	}
+
+	testCases := []testData{
+		{source: SourceClose, expected: 3},
+		{source: SourceOpen, expected: 1},
+		{source: SourceHigh, expected: 4},
+		{source: SourceLow, expected: 1},
+		{source: SourceHL2, expected: 2.5},
+		{source: SourceHLC3, expected: 8.0 / 3},
+		{source: SourceTypical, expected: 8.0 / 3},
+		{source: SourceOHLC4, expected: 2.25},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.source.Price(candle))
+	}
+}
+
+func TestPrices(t *testing.T) {
+	assert.Equal(t, []float64{2.5, 4, 6}, Prices(testCandles, SourceHL2))
+	assert.Equal(t, []float64{}, Prices(nil, SourceClose))
+}
+
+func TestIndicator_SMACandles(t *testing.T) {
+	indicator := NewIndicator(NewExmo(Test()))
+
+	result, err := indicator.SMACandles(testCandles, 2, SourceOpen)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 2, 4}, result, 1e-9)
+
+	streaming, err := NewStreamingSMA(2, SourceOpen)
+	assert.NoError(t, err)
+	for j, candle := range testCandles {
+		streaming.Update(candle)
+		assertSeries(t, result[j:j+1], []float64{streaming.Value()}, 1e-9)
+	}
+
+	_, err = indicator.SMACandles(testCandles, 0, SourceOpen)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestIndicator_EMACandles(t *testing.T) {
+	indicator := NewIndicator(NewExmo(Test()))
+
+	result, err := indicator.EMACandles(testCandles, 2, SourceClose)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 4, 4}, result, 1e-9)
+
+	_, err = indicator.EMACandles(testCandles, -1, SourceClose)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestWithPriceSource(t *testing.T) {
+	indicator := NewIndicator(NewExmo(Test()), WithPriceSource(SourceOHLC4))
+	assert.Equal(t, SourceOHLC4, indicator.source)
+
+	result, err := indicator.SMA("ADA_BTC", 30, 1, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1.5}, result)
+}
+
+func TestIndicator_GetCandles(t *testing.T) {
+	indicator := NewIndicator(NewExmo(Test()))
+
+	result, err := indicator.GetCandles("ADA_BTC", 30, time.Unix(1701289470, 0), time.Unix(1701293070, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []Candle{{C: 1}, {C: 2}, {C: 3}}, result)
+
+	_, err = indicator.GetCandles("BTC_USD", 30, time.Unix(1701289470, 0), time.Unix(1701293070, 0))
+	assert.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+type PivotMethod int
+
+const (
+	PivotClassic PivotMethod = iota
+	PivotFibonacci
+	PivotCamarilla
+	// PivotWoodie weights the close of the prior period twice.
+	PivotWoodie
+)
+
+// PivotLevels are the pivot point with its resistance and support levels. The levels a method
+// does not define, like R4 and S4 of all but Camarilla, are NaN.
+type PivotLevels struct {
+	Pivot          float64
+	R1, R2, R3, R4 float64
+	S1, S2, S3, S4 float64
+}
+
+// PeriodPivots are the levels for the period starting at Start, calculated from the period before it.
+type PeriodPivots struct {
+	Start  time.Time
+	Levels PivotLevels
+}
+
+// Pivots calculates the pivot levels from the candle of the prior period.
+func Pivots(prior Candle, method PivotMethod) (PivotLevels, error) {
+	high, low, closePrice := prior.H, prior.L, prior.C
+	r := high - low
+	nan := math.NaN()
+
+	switch method {
+	case PivotClassic, PivotWoodie:
+		p := (high + low + closePrice) / 3
+		if method == PivotWoodie {
+			p = (high + low + 2*closePrice) / 4
+		}
+		return PivotLevels{
+			Pivot: p,
+			R1:    2*p - low, R2: p + r, R3: high + 2*(p-low), R4: nan,
+			S1: 2*p - high, S2: p - r, S3: low - 2*(high-p), S4: nan,
+		}, nil
+
+	case PivotFibonacci:
+		p := (high + low + closePrice) / 3
+		return PivotLevels{
+			Pivot: p,
+			R1:    p + 0.382*r, R2: p + 0.618*r, R3: p + r, R4: nan,
+			S1: p - 0.382*r, S2: p - 0.618*r, S3: p - r, S4: nan,
+		}, nil
+
+	case PivotCamarilla:
+		p := (high + low + closePrice) / 3
+		step := r * 1.1
+		return PivotLevels{
+			Pivot: p,
+			R1:    closePrice + step/12, R2: closePrice + step/6, R3: closePrice + step/4, R4: closePrice + step/2,
+			S1: closePrice - step/12, S2: closePrice - step/6, S3: closePrice - step/4, S4: closePrice - step/2,
+		}, nil
+	}
+	return PivotLevels{}, fmt.Errorf("Pivots -> unknown method %d: %w", method, ErrInvalidParams)
+}
+
+// PivotPoints resamples candles into periods of the given resolution, aligned like Resample,
+// and calculates the levels of every period from the one before it. The last levels are for
+// the period after the last candle.
+func PivotPoints(candles []Candle, period time.Duration, loc *time.Location, method PivotMethod) ([]PeriodPivots, error) {
+	periods, err := Resample(candles, period, loc)
+	if err != nil {
+		return nil, fmt.Errorf("PivotPoints -> %w", err)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	res := make([]PeriodPivots, 0, len(periods))
+	for i, prior := range periods {
+		levels, err := Pivots(prior, method)
+		if err != nil {
+			return nil, fmt.Errorf("PivotPoints -> %w", err)
+		}
+
+		start := nextBucket(time.UnixMilli(prior.T).In(loc), period)
+		if i+1 < len(periods) {
+			start = time.UnixMilli(periods[i+1].T).In(loc)
+		}
+		res = append(res, PeriodPivots{Start: start, Levels: levels})
+	}
+	return res, nil
+}
+
+func nextBucket(start time.Time, resolution time.Duration) time.Time {
+	switch resolution {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Day:
+		return start.AddDate(0, 0, 1)
+	}
+	return start.Add(resolution)
+}
+
+// Swing is a candle whose high (for a swing high) or low is the extreme of the candles around it.
+type Swing struct {
+	Index int
+	Time  time.Time
+	Price float64
+	High  bool
+}
+
+// DetectSwings finds the candles whose high is above, or whose low is below, the highs or
+// the lows of strength candles on both sides. Equal neighbours break a swing.
+func DetectSwings(candles []Candle, strength int) ([]Swing, error) {
+	if strength <= 0 {
+		return nil, fmt.Errorf("DetectSwings -> %w", ErrInvalidPeriod)
+	}
+
+	res := make([]Swing, 0)
+	for i := strength; i+strength < len(candles); i++ {
+		isHigh, isLow := true, true
+		for j := i - strength; j <= i+strength; j++ {
+			if j == i {
+				continue
+			}
+			isHigh = isHigh && candles[j].H < candles[i].H
+			isLow = isLow && candles[j].L > candles[i].L
+		}
+
+		t := time.UnixMilli(candles[i].T)
+		if isHigh {
+			res = append(res, Swing{Index: i, Time: t, Price: candles[i].H, High: true})
+		}
+		if isLow {
+			res = append(res, Swing{Index: i, Time: t, Price: candles[i].L})
+		}
+	}
+	return res, nil
+}
+
+// Zone is a price range where swings cluster. It is a support below the last close
+// and a resistance above it.
+type Zone struct {
+	Low     float64
+	High    float64
+	Price   float64
+	Touches int
+	Last    time.Time
+	Support bool
+}
+
+// SupportResistance clusters the swings of candles into zones. Sorted by price, a swing joins
+// the zone before it if it is within tolerance, relative to the price, of the zone average.
+// Zones with fewer than minTouches swings are dropped.
+func SupportResistance(candles []Candle, strength int, tolerance float64, minTouches int) ([]Zone, error) {
+	if tolerance < 0 || minTouches <= 0 {
+		return nil, fmt.Errorf("SupportResistance -> %w", ErrInvalidParams)
+	}
+	swings, err := DetectSwings(candles, strength)
+	if err != nil {
+		return nil, fmt.Errorf("SupportResistance -> %w", err)
+	}
+
+	sort.Slice(swings, func(i, j int) bool { return swings[i].Price < swings[j].Price })
+
+	zones := make([]Zone, 0)
+	for _, swing := range swings {
+		if n := len(zones); n > 0 && swing.Price-zones[n-1].Price <= tolerance*zones[n-1].Price {
+			zone := &zones[n-1]
+			zone.Price = (zone.Price*float64(zone.Touches) + swing.Price) / float64(zone.Touches+1)
+			zone.High = swing.Price
+			zone.Touches++
+			if swing.Time.After(zone.Last) {
+				zone.Last = swing.Time
+			}
+			continue
+		}
+		zones = append(zones, Zone{Low: swing.Price, High: swing.Price, Price: swing.Price, Touches: 1, Last: swing.Time})
+	}
+
+	res := make([]Zone, 0, len(zones))
+	for _, zone := range zones {
+		if zone.Touches < minTouches {
+			continue
+		}
+		zone.Support = zone.Price < candles[len(candles)-1].C
+		res = append(res, zone)
+	}
+	return res, nil
+}
+
+// PivotPoints calculates the pivot levels of pair for the periods between from and to. Daily
+// and longer periods start at midnight in loc, UTC when loc is nil.
+func (i *Indicator) PivotPoints(pair string, limit int, period time.Duration, loc *time.Location, method PivotMethod, from, to time.Time) ([]PeriodPivots, error) {
+	candles, err := i.GetCandles(pair, limit, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_PivotPoints -> %w", err)
+	}
+
+	res, err := PivotPoints(candles, period, loc, method)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_PivotPoints -> %w", err)
+	}
+	return res, nil
+}
+
+// SupportResistance finds the support and resistance zones of pair between from and to.
+func (i *Indicator) SupportResistance(pair string, limit, strength int, tolerance float64, minTouches int, from, to time.Time) ([]Zone, error) {
+	candles, err := i.GetCandles(pair, limit, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_SupportResistance -> %w", err)
+	}
+
+	res, err := SupportResistance(candles, strength, tolerance, minTouches)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_SupportResistance -> %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func highCandles(highs ...float64) []Candle {
+	res := make([]Candle, 0, len(highs))
+	for i, high := range highs {
+		res = append(res, Candle{T: int64(i) * 60000, O: high - 0.5, H: high, L: high - 1, C: high - 0.5})
+	}
+	return res
+}
+
+func TestPivots(t *testing.T) {
+	nan := math.NaN()
+	prior := Candle{H: 110, L: 90, C: 100}
+
+	type testData struct {
+		name     string
+		method   PivotMethod
+		prior    Candle
+		expected []float64
+	}
+
+	testCases := []testData{
+		{name: "classic", method: PivotClassic, prior: prior, expected: []float64{100, 110, 120, 130, nan, 90, 80, 70, nan}},
+		{name: "fibonacci", method: PivotFibonacci, prior: prior, expected: []float64{100, 107.64, 112.36, 120, nan, 92.36, 87.64, 80, nan}},
+		{name: "camarilla", method: PivotCamarilla, prior: prior, expected: []float64{100, 101.8333, 103.6667, 105.5, 111, 98.1667, 96.3333, 94.5, 89}},
+		{name: "woodie", method: PivotWoodie, prior: Candle{H: 110, L: 90, C: 104}, expected: []float64{102, 114, 122, 134, nan, 94, 82, 74, nan}},
+	}
+
+	for _, tc := range testCases {
+		levels, err := Pivots(tc.prior, tc.method)
+
+		assert.NoError(t, err, tc.name)
+		assertSeries(t, tc.expected, []float64{levels.Pivot, levels.R1, levels.R2, levels.R3, levels.R4,
+			levels.S1, levels.S2, levels.S3, levels.S4}, 1e-4)
+	}
+
+	_, err := Pivots(prior, PivotMethod(10))
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestPivotPoints(t *testing.T) {
+	candles := []Candle{
+		{T: 0, O: 98, H: 110, L: 95, C: 100},
+		{T: (12 * time.Hour).Milliseconds(), O: 100, H: 105, L: 90, C: 100},
+		{T: Day.Milliseconds(), O: 100, H: 120, L: 100, C: 110},
+	}
+
+	result, err := PivotPoints(candles, Day, time.UTC, PivotClassic)
+
+	assert.NoError(t, err)
+	if assert.Len(t, result, 2) {
+		assert.Equal(t, time.Unix(0, 0).UTC().Add(Day), result[0].Start)
+		assert.InDelta(t, 100, result[0].Levels.Pivot, 1e-9)
+		assert.Equal(t, time.Unix(0, 0).UTC().Add(2*Day), result[1].Start)
+		assert.InDelta(t, 110, result[1].Levels.Pivot, 1e-9)
+	}
+
+	_, err = PivotPoints(candles, 7*time.Hour, time.UTC, PivotClassic)
+	assert.ErrorIs(t, err, ErrUnsupportedResolution)
+}
+
+func TestIndicator_PivotPoints(t *testing.T) {
+	exchange := candlesExchange(`[{"t":0,"o":98,"c":100,"h":110,"l":95,"v":1},{"t":43200000,"o":100,"c":100,"h":105,"l":90,"v":1},{"t":86400000,"o":100,"c":110,"h":120,"l":100,"v":1}]`)
+	indicator := NewIndicator(exchange)
+	loc := time.FixedZone("UTC+12", 12*60*60)
+
+	result, err := indicator.PivotPoints("BTC_USD", 720, Day, loc, PivotClassic, time.Unix(0, 0), time.Unix(86400, 0))
+
+	assert.NoError(t, err)
+	if assert.Len(t, result, 2) {
+		assert.True(t, time.Unix(0, 0).Add(12*time.Hour).Equal(result[0].Start))
+		assert.Equal(t, loc, result[0].Start.Location())
+		assert.InDelta(t, 305.0/3, result[0].Levels.Pivot, 1e-9)
+		assert.True(t, time.Unix(0, 0).Add(36*time.Hour).Equal(result[1].Start))
+		assert.InDelta(t, 320.0/3, result[1].Levels.Pivot, 1e-9)
+	}
+}
+
+func TestDetectSwings(t *testing.T) {
+	candles := highCandles(1, 3, 2, 5, 4, 4, 6)
+
+	swings, err := DetectSwings(candles, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Swing{
+		{Index: 1, Time: time.UnixMilli(60000), Price: 3, High: true},
+		{Index: 2, Time: time.UnixMilli(120000), Price: 1},
+		{Index: 3, Time: time.UnixMilli(180000), Price: 5, High: true},
+	}, swings)
+
+	swings, err = DetectSwings(candles, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, swings)
+
+	_, err = DetectSwings(candles, 0)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestSupportResistance(t *testing.T) {
+	candles := highCandles(10, 12, 10, 12.1, 10, 8, 10, 8.05, 10)
+
+	zones, err := SupportResistance(candles, 1, 0.01, 2)
+
+	assert.NoError(t, err)
+	if assert.Len(t, zones, 2) {
+		assert.InDelta(t, 7, zones[0].Low, 1e-9)
+		assert.InDelta(t, 7.05, zones[0].High, 1e-9)
+		assert.InDelta(t, 7.025, zones[0].Price, 1e-9)
+		assert.Equal(t, 2, zones[0].Touches)
+		assert.Equal(t, time.UnixMilli(7*60000), zones[0].Last)
+		assert.True(t, zones[0].Support)
+
+		assert.InDelta(t, 12.05, zones[1].Price, 1e-9)
+		assert.Equal(t, time.UnixMilli(3*60000), zones[1].Last)
+		assert.False(t, zones[1].Support)
+	}
+
+	zones, err = SupportResistance(candles, 1, 0.01, 1)
+	assert.NoError(t, err)
+	assert.Len(t, zones, 4)
+
+	_, err = SupportResistance(candles, 1, -1, 1)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestIndicator_SupportResistance(t *testing.T) {
+	exchange := candlesExchange(`[{"t":0,"o":1,"c":1,"h":2,"l":0},{"t":60000,"o":1,"c":3,"h":4,"l":1},{"t":120000,"o":3,"c":2,"h":3,"l":2}]`)
+	indicator := NewIndicator(exchange)
+
+	zones, err := indicator.SupportResistance("BTC_USD", 1, 1, 0.01, 1, time.Unix(0, 0), time.Unix(180, 0))
+
+	assert.NoError(t, err)
+	if assert.Len(t, zones, 1) {
+		assert.Equal(t, 4.0, zones[0].Price)
+		assert.False(t, zones[0].Support)
+	}
+}
@@ -10,6 +10,33 @@ import (
 type Indicatorer interface {
 	SMA(pair string, limit, period int, from, to time.Time) ([]float64, error)
 	EMA(pair string, limit, period int, from, to time.Time) ([]float64, error)
+	RSI(pair string, limit, period int, from, to time.Time) ([]float64, error)
+	MACD(pair string, limit, fast, slow, signal int, from, to time.Time) (MACDResult, error)
+	Stochastic(pair string, limit, kPeriod, dPeriod int, from, to time.Time) (StochasticResult, error)
+	BollingerBands(pair string, limit, period int, k float64, from, to time.Time) (Bands, error)
+	ATR(pair string, limit, period int, from, to time.Time) ([]float64, error)
+	KeltnerChannels(pair string, limit, period, atrPeriod int, multiplier float64, from, to time.Time) (Bands, error)
+	DonchianChannels(pair string, limit, period int, from, to time.Time) (Bands, error)
+	VWAP(pair string, limit int, from, to time.Time, loc *time.Location) ([]float64, error)
+	RollingVWAP(pair string, limit, period int, from, to time.Time) ([]float64, error)
+	TradesVWAP(pair string) (float64, error)
+	OBV(pair string, limit int, from, to time.Time) ([]float64, error)
+	MFI(pair string, limit, period int, from, to time.Time) ([]float64, error)
+	AccumulationDistribution(pair string, limit int, from, to time.Time) ([]float64, error)
+	DMI(pair string, limit, period int, from, to time.Time) (DMIResult, error)
+	ParabolicSAR(pair string, limit int, step, maxStep float64, from, to time.Time) ([]float64, error)
+	Ichimoku(pair string, limit, tenkan, kijun, senkouB int, from, to time.Time) (IchimokuResult, error)
+	MovingAverage(name string, pair string, limit, period int, from, to time.Time) ([]float64, error)
+	Evaluate(spec string, pair string, limit int, from, to time.Time) (Output, error)
+	EvaluateExpression(expression string, pair string, limit int, from, to time.Time) (ExpressionResult, error)
+	Patterns(pair string, limit int, from, to time.Time, patterns ...Pattern) ([]PatternMatch, error)
+	PivotPoints(pair string, limit int, period time.Duration, loc *time.Location, method PivotMethod, from, to time.Time) ([]PeriodPivots, error)
+	SupportResistance(pair string, limit, strength int, tolerance float64, minTouches int, from, to time.Time) ([]Zone, error)
+	Returns(pair string, limit int, kind ReturnKind, from, to time.Time) (Series, error)
+	Volatility(pair string, limit, period int, from, to time.Time) (Series, error)
+	CorrelationMatrix(pairs []string, limit, period int, from, to time.Time) ([]CorrelationMatrix, error)
+	Beta(pair, reference string, limit int, from, to time.Time) (float64, error)
+	Drawdowns(pair string, limit int, from, to time.Time) (DrawdownStats, error)
 }
 
 type Exchanger interface {
@@ -17,6 +44,7 @@ type Exchanger interface {
 	GetTrades(pairs ...string) (Trades, error)
 	GetOrderBook(limit int, pairs ...string) (OrderBook, error)
 	GetCurrencies() (Currencies, error)
+	GetPairSettings() (PairSettings, error)
 	GetCandlesHistory(pair string, limit int, start, end time.Time) (CandlesHistory, error)
 	GetClosePrice(pair string, limit int, start, end time.Time) ([]float64, error)
 }
@@ -26,26 +54,29 @@ type Requester interface {
 }
 
 type Indicator struct {
-	exchange     Exchanger
-	calculateSMA func(data []float64, period int) []float64
-	calculateEMA func(data []float64, period int) []float64
+	exchange Exchanger
+	registry *Registry
+	source   PriceSource
+	chart    ChartTransform
+	sma      MovingAverage
+	ema      MovingAverage
+	periods  map[string]MovingAverage
+	now      func() time.Time
 }
 
+const (
+	// periodsSMA and periodsEMA are the averages SMA and EMA apply to the period buckets of
+	// GetDataPerPeriods. They are replaced by WithSMA and WithEMA and are not in the registry.
+	periodsSMA = "periods_sma"
+	periodsEMA = "periods_ema"
+)
+
 func (i *Indicator) GetDataPerPeriods(pair string, limit, period int, from, to time.Time) ([]float64, error) {
 	var sum float64
 	data := make([]float64, 0, period)
-	onePeriodTime := to.Sub(from).Hours() / float64(period)
-	tillEnd, _ := time.ParseDuration(fmt.Sprintf("%fh", onePeriodTime))
-	end := from
-
-	for j := 0; j < period; j++ {
-
-		end = end.Add(tillEnd)
-		if to.Before(end) || j == period-1 {
-			end = to
-		}
 
-		dataOfOnePeriod, err := i.exchange.GetClosePrice(pair, limit, from, end)
+	for _, end := range periodEnds(period, from, to) {
+		dataOfOnePeriod, err := i.getPrices(pair, limit, from, end)
 		if err != nil {
 			return nil, fmt.Errorf("Indicator_GetDataPerPeriods -> %w", err)
 		}
@@ -61,35 +92,87 @@ func (i *Indicator) GetDataPerPeriods(pair string, limit, period int, from, to t
 	return data, nil
 }
 
+// periodEnds splits the time between from and to into period equal parts and returns their ends.
+func periodEnds(period int, from, to time.Time) []time.Time {
+	ends := make([]time.Time, 0, period)
+	onePeriodTime := to.Sub(from).Hours() / float64(period)
+	tillEnd, _ := time.ParseDuration(fmt.Sprintf("%fh", onePeriodTime))
+	end := from
+
+	for j := 0; j < period; j++ {
+
+		end = end.Add(tillEnd)
+		if to.Before(end) || j == period-1 {
+			end = to
+		}
+		ends = append(ends, end)
+	}
+
+	return ends
+}
+
+func (i *Indicator) getPrices(pair string, limit int, from, to time.Time) ([]float64, error) {
+	if i.source == SourceClose && i.chart == nil {
+		return i.exchange.GetClosePrice(pair, limit, from, to)
+	}
+
+	candles, err := i.GetCandles(pair, limit, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return Prices(candles, i.source), nil
+}
+
 func (i *Indicator) SMA(pair string, limit, period int, from, to time.Time) ([]float64, error) {
-	data, err := i.GetDataPerPeriods(pair, limit, period, from, to)
+	res, err := i.SMASeries(pair, limit, period, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("Indicator_SMA -> %w", err)
 	}
-
-	return i.calculateSMA(data, period), nil
+	return res.Values(), nil
 }
 
 func (i *Indicator) EMA(pair string, limit, period int, from, to time.Time) ([]float64, error) {
-	data, err := i.GetDataPerPeriods(pair, limit, period, from, to)
+	res, err := i.EMASeries(pair, limit, period, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("Indicator_EMA -> %w", err)
 	}
+	return res.Values(), nil
+}
 
-	return i.calculateEMA(data, period), nil
+func (i *Indicator) calculatePeriods(name string, data []float64, period int) ([]float64, error) {
+	if period <= 0 {
+		return nil, ErrInvalidPeriod
+	}
+	return i.periods[name](data, period), nil
 }
 
 type IndicatorOption func(*Indicator)
 
 func NewIndicator(exchange Exchanger, opts ...IndicatorOption) *Indicator {
 	i := &Indicator{
-		exchange:     exchange,
-		calculateEMA: calculateEMA,
-		calculateSMA: calculateSMA,
+		exchange: exchange,
+		registry: NewRegistry(),
+		periods:  map[string]MovingAverage{periodsSMA: calculateSMA, periodsEMA: calculateEMA},
+		now:      time.Now,
 	}
 	for _, opt := range opts {
 		opt(i)
 	}
+
+	// The averages of WithSMA and WithEMA replace the default ones in a copy of the registry,
+	// so a registry shared by WithRegistry is left as it is.
+	var overrides []Definition
+	if i.sma != nil {
+		i.periods[periodsSMA] = i.sma
+		overrides = append(overrides, movingAverageDefinition("sma", i.sma, 20))
+	}
+	if i.ema != nil {
+		i.periods[periodsEMA] = i.ema
+		overrides = append(overrides, movingAverageDefinition("ema", i.ema, 20))
+	}
+	if len(overrides) > 0 {
+		i.registry = i.registry.clone(overrides...)
+	}
 	return i
 }
 
@@ -105,9 +188,10 @@ func calculateSMA(data []float64, period int) []float64 {
 	return res
 }
 
+// WithSMA replaces the simple average of the indicator, both of SMA and of the candle-based indicators.
 func WithSMA(SMA func(data []float64, period int) []float64) IndicatorOption {
 	return func(i *Indicator) {
-		i.calculateSMA = SMA
+		i.sma = SMA
 	}
 }
 
@@ -126,9 +210,10 @@ func calculateEMA(data []float64, period int) []float64 {
 	return res
 }
 
+// WithEMA replaces the exponential average of the indicator, both of EMA and of the candle-based indicators.
 func WithEMA(EMA func(data []float64, period int) []float64) IndicatorOption {
 	return func(i *Indicator) {
-		i.calculateEMA = EMA
+		i.ema = EMA
 	}
 }
 
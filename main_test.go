@@ -20,8 +20,9 @@ func TestWithSMA(t *testing.T) {
 	}
 
 	result := NewIndicator(exmo, WithSMA(TestSMA))
-	returnedSMA := result.calculateSMA([]float64{}, 2)
+	returnedSMA, err := result.calculatePeriods(periodsSMA, []float64{}, 2)
 
+	assert.NoError(t, err)
 	assert.NotEqual(t, *result, Indicator{})
 	assert.NotNil(t, returnedSMA)
 	assert.Equal(t, expected, returnedSMA)
@@ -35,8 +36,9 @@ func TestWithEMA(t *testing.T) {
 	}
 
 	result := NewIndicator(exmo, WithEMA(TestEMA))
-	returnedEMA := result.calculateEMA([]float64{}, 2)
+	returnedEMA, err := result.calculatePeriods(periodsEMA, []float64{}, 2)
 
+	assert.NoError(t, err)
 	assert.NotEqual(t, *result, Indicator{})
 	assert.NotNil(t, returnedEMA)
 	assert.Equal(t, expected, returnedEMA)
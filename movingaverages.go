@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+var ErrUnknownMovingAverage = errors.New("unknown moving average")
+
+// MovingAverage calculates a moving average with the candle-based indicators convention: the result
+// has the same length as data and holds NaN until period values are available. Any MovingAverage
+// can be passed to WithSMA and WithEMA.
+type MovingAverage func(data []float64, period int) []float64
+
+var movingAverages = map[string]MovingAverage{
+	"sma":  simpleAverage,
+	"ema":  exponentialAverage,
+	"wma":  calculateWMA,
+	"dema": calculateDEMA,
+	"tema": calculateTEMA,
+	"hma":  calculateHMA,
+	"kama": calculateKAMA,
+	"smma": wilderAverage,
+}
+
+func MovingAverageByName(name string) (MovingAverage, error) {
+	movingAverage, ok := movingAverages[name]
+	if !ok {
+		return nil, fmt.Errorf("MovingAverageByName -> %q: %w", name, ErrUnknownMovingAverage)
+	}
+	return movingAverage, nil
+}
+
+func MovingAverageNames() []string {
+	res := make([]string, 0, len(movingAverages))
+	for name := range movingAverages {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// MovingAverage calculates the moving average called name over the candles of pair.
+func (i *Indicator) MovingAverage(name string, pair string, limit, period int, from, to time.Time) ([]float64, error) {
+	res, err := i.MovingAverageSeries(name, pair, limit, period, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_MovingAverage -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+// MovingAverageSeries is MovingAverage with every value paired with its candle.
+func (i *Indicator) MovingAverageSeries(name string, pair string, limit, period int, from, to time.Time) (Series, error) {
+	movingAverage, err := MovingAverageByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_MovingAverageSeries -> %w", err)
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_MovingAverageSeries -> %w", ErrInvalidPeriod)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return valueOutput(movingAverage(Prices(candles, i.source), period))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_MovingAverageSeries -> %w", err)
+	}
+	return res[ValueLine], nil
+}
+
+// calculateWMA weights the values of the window linearly, the latest one by period.
+func calculateWMA(data []float64, period int) []float64 {
+	res := nanSeries(len(data))
+	denominator := float64(period*(period+1)) / 2
+	for i := period - 1; i < len(data); i++ {
+		var sum float64
+		for j, value := range data[i-period+1 : i+1] {
+			sum += value * float64(j+1)
+		}
+		res[i] = sum / denominator
+	}
+	return res
+}
+
+func calculateDEMA(data []float64, period int) []float64 {
+	ema := exponentialAverage(data, period)
+	emaOfEMA := exponentialAverage(ema, period)
+
+	res := make([]float64, len(data))
+	for i := range data {
+		res[i] = 2*ema[i] - emaOfEMA[i]
+	}
+	return res
+}
+
+func calculateTEMA(data []float64, period int) []float64 {
+	ema := exponentialAverage(data, period)
+	emaOfEMA := exponentialAverage(ema, period)
+	emaOfEMAOfEMA := exponentialAverage(emaOfEMA, period)
+
+	res := make([]float64, len(data))
+	for i := range data {
+		res[i] = 3*ema[i] - 3*emaOfEMA[i] + emaOfEMAOfEMA[i]
+	}
+	return res
+}
+
+// calculateHMA is the WMA over sqrt(period) of 2*WMA(period/2) - WMA(period).
+func calculateHMA(data []float64, period int) []float64 {
+	half, full := calculateWMA(data, maxInt(period/2, 1)), calculateWMA(data, period)
+
+	diff := make([]float64, len(data))
+	for i := range data {
+		diff[i] = 2*half[i] - full[i]
+	}
+
+	res := nanSeries(len(data))
+	sqrtPeriod := maxInt(int(math.Round(math.Sqrt(float64(period)))), 1)
+	start := firstValid(diff, sqrtPeriod)
+	if start < 0 {
+		return res
+	}
+	copy(res[start:], calculateWMA(diff[start:], sqrtPeriod))
+	return res
+}
+
+// calculateKAMA is Kaufman's adaptive average with the classic fast and slow periods of 2 and 30.
+// It starts from the value at index period-1 of the first run without NaN.
+func calculateKAMA(data []float64, period int) []float64 {
+	res := nanSeries(len(data))
+	start := firstValid(data, period)
+	if start < 0 {
+		return res
+	}
+
+	fast, slow := 2.0/3, 2.0/31
+	kama := data[start+period-1]
+	res[start+period-1] = kama
+	for i := start + period; i < len(data); i++ {
+		var volatility float64
+		for j := i - period + 1; j <= i; j++ {
+			volatility += math.Abs(data[j] - data[j-1])
+		}
+
+		efficiency := 0.0
+		if volatility != 0 {
+			efficiency = math.Abs(data[i]-data[i-period]) / volatility
+		}
+		smoothing := math.Pow(efficiency*(fast-slow)+slow, 2)
+		kama += smoothing * (data[i] - kama)
+		res[i] = kama
+	}
+	return res
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
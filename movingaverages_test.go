@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovingAverageByName(t *testing.T) {
+	data := []float64{3, 3, 3, 3, 3, 3, 3, 3, 3, 3}
+	for _, name := range MovingAverageNames() {
+		movingAverage, err := MovingAverageByName(name)
+		assert.NoError(t, err)
+
+		result := movingAverage(data, 3)
+		assert.Len(t, result, len(data))
+		assert.True(t, math.IsNaN(result[0]), name)
+		assert.InDelta(t, 3, result[len(result)-1], 1e-9, name)
+	}
+
+	_, err := MovingAverageByName("unknown")
+	assert.ErrorIs(t, err, ErrUnknownMovingAverage)
+}
+
+func TestMovingAverageNames(t *testing.T) {
+	assert.Equal(t, []string{"dema", "ema", "hma", "kama", "sma", "smma", "tema", "wma"}, MovingAverageNames())
+}
+
+func Test_movingAverages(t *testing.T) {
+	nan := math.NaN()
+	data := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+
+	type testData struct {
+		name     string
+		period   int
+		expected []float64
+	}
+
+	testCases := []testData{
+		{name: "wma", period: 3, expected: []float64{nan, nan, 14.0 / 6, 20.0 / 6, 26.0 / 6, 32.0 / 6, 38.0 / 6, 44.0 / 6}},
+		{name: "dema", period: 2, expected: []float64{nan, nan, 3, 4, 5, 6, 7, 8}},
+		{name: "tema", period: 2, expected: []float64{nan, nan, nan, 4, 5, 6, 7, 8}},
+		{name: "hma", period: 4, expected: []float64{nan, nan, nan, nan, 5, 6, 7, 8}},
+		{name: "smma", period: 2, expected: []float64{nan, 1.5, 2.25, 3.125, 4.0625, 5.03125, 6.015625, 7.0078125}},
+	}
+
+	for _, tc := range testCases {
+		movingAverage, err := MovingAverageByName(tc.name)
+		assert.NoError(t, err)
+
+		assertSeries(t, tc.expected, movingAverage(data, tc.period), 1e-9)
+	}
+}
+
+func Test_calculateKAMA(t *testing.T) {
+	nan := math.NaN()
+
+	assertSeries(t, []float64{nan, 2, 22.0 / 9, 22.0/9 + 4.0/9*(4-22.0/9)}, calculateKAMA([]float64{1, 2, 3, 4}, 2), 1e-9)
+	assertSeries(t, []float64{nan, 2, 2}, calculateKAMA([]float64{2, 2, 2}, 2), 1e-9)
+	assertSeries(t, []float64{nan, nan}, calculateKAMA([]float64{1, 2}, 3), 0)
+}
+
+func TestIndicator_MovingAverage(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"c":1},{"c":2},{"c":3}]`))
+	from, to := time.Unix(1701289470, 0), time.Unix(1701300270, 0)
+
+	result, err := indicator.MovingAverage("wma", "ADA_BTC", 30, 2, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 5.0 / 3, 8.0 / 3}, result, 1e-9)
+
+	_, err = indicator.MovingAverage("unknown", "ADA_BTC", 30, 2, from, to)
+	assert.ErrorIs(t, err, ErrUnknownMovingAverage)
+
+	_, err = indicator.MovingAverage("wma", "ADA_BTC", 30, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestIndicator_MovingAverageSeries(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"t":0,"c":1},{"t":1800000,"c":2},{"t":3600000,"c":3}]`))
+	from, to := time.Unix(0, 0), time.Unix(5400, 0)
+
+	result, err := indicator.MovingAverageSeries("wma", "ADA_BTC", 30, 2, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 5.0 / 3, 8.0 / 3}, result.Values(), 1e-9)
+	assert.Equal(t, time.Unix(3600, 0), result[2].Start)
+
+	_, err = indicator.MovingAverageSeries("unknown", "ADA_BTC", 30, 2, from, to)
+	assert.ErrorIs(t, err, ErrUnknownMovingAverage)
+
+	_, err = indicator.MovingAverageSeries("wma", "ADA_BTC", 30, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestWithSMA_movingAverage(t *testing.T) {
+	wma, err := MovingAverageByName("wma")
+	assert.NoError(t, err)
+
+	indicator := NewIndicator(NewExmo(Test()), WithSMA(wma))
+
+	result, err := indicator.SMACandles(testCandles, 2, SourceClose)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 13.0 / 3, 13.0 / 3}, result, 1e-9)
+}
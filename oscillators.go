@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type MACDResult struct {
+	MACD      []float64
+	Signal    []float64
+	Histogram []float64
+}
+
+type StochasticResult struct {
+	K []float64
+	D []float64
+}
+
+func (i *Indicator) RSI(pair string, limit, period int, from, to time.Time) ([]float64, error) {
+	res, err := i.RSISeries(pair, limit, period, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_RSI -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+// RSISeries is RSI with every value paired with its candle.
+func (i *Indicator) RSISeries(pair string, limit, period int, from, to time.Time) (Series, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_RSISeries -> %w", ErrInvalidPeriod)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return valueOutput(calculateRSI(Prices(candles, i.source), period))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_RSISeries -> %w", err)
+	}
+	return res[ValueLine], nil
+}
+
+func (i *Indicator) MACD(pair string, limit, fast, slow, signal int, from, to time.Time) (MACDResult, error) {
+	res, err := i.MACDSeries(pair, limit, fast, slow, signal, from, to)
+	if err != nil {
+		return MACDResult{}, fmt.Errorf("Indicator_MACD -> %w", err)
+	}
+	return MACDResult{MACD: res["macd"].Values(), Signal: res["signal"].Values(), Histogram: res["histogram"].Values()}, nil
+}
+
+// MACDSeries is MACD with the "macd", "signal" and "histogram" lines paired with their candles.
+func (i *Indicator) MACDSeries(pair string, limit, fast, slow, signal int, from, to time.Time) (SeriesOutput, error) {
+	if fast <= 0 || slow <= 0 || signal <= 0 {
+		return nil, fmt.Errorf("Indicator_MACDSeries -> %w", ErrInvalidPeriod)
+	}
+	if fast >= slow {
+		return nil, fmt.Errorf("Indicator_MACDSeries -> fast period must be less than slow: %w", ErrInvalidParams)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		macd := calculateMACD(Prices(candles, i.source), fast, slow, signal)
+		return Output{"macd": macd.MACD, "signal": macd.Signal, "histogram": macd.Histogram}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_MACDSeries -> %w", err)
+	}
+	return res, nil
+}
+
+func (i *Indicator) Stochastic(pair string, limit, kPeriod, dPeriod int, from, to time.Time) (StochasticResult, error) {
+	res, err := i.StochasticSeries(pair, limit, kPeriod, dPeriod, from, to)
+	if err != nil {
+		return StochasticResult{}, fmt.Errorf("Indicator_Stochastic -> %w", err)
+	}
+	return StochasticResult{K: res["k"].Values(), D: res["d"].Values()}, nil
+}
+
+// StochasticSeries is Stochastic with the "k" and "d" lines paired with their candles.
+func (i *Indicator) StochasticSeries(pair string, limit, kPeriod, dPeriod int, from, to time.Time) (SeriesOutput, error) {
+	if kPeriod <= 0 || dPeriod <= 0 {
+		return nil, fmt.Errorf("Indicator_StochasticSeries -> %w", ErrInvalidPeriod)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		stochastic := calculateStochastic(candles, kPeriod, dPeriod)
+		return Output{"k": stochastic.K, "d": stochastic.D}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_StochasticSeries -> %w", err)
+	}
+	return res, nil
+}
+
+// calculateRSI uses Wilder smoothing of gains and losses. The first value is at index period.
+func calculateRSI(data []float64, period int) []float64 {
+	res := nanSeries(len(data))
+	if len(data) <= period {
+		return res
+	}
+
+	gains := make([]float64, len(data))
+	losses := make([]float64, len(data))
+	gains[0], losses[0] = math.NaN(), math.NaN()
+	for i := 1; i < len(data); i++ {
+		change := data[i] - data[i-1]
+		gains[i], losses[i] = math.Max(change, 0), math.Max(-change, 0)
+	}
+
+	avgGains, avgLosses := wilderAverage(gains, period), wilderAverage(losses, period)
+	for i := period; i < len(data); i++ {
+		if avgLosses[i] == 0 {
+			res[i] = 100
+			continue
+		}
+		res[i] = 100 - 100/(1+avgGains[i]/avgLosses[i])
+	}
+	return res
+}
+
+func calculateMACD(data []float64, fast, slow, signal int) MACDResult {
+	fastEMA, slowEMA := exponentialAverage(data, fast), exponentialAverage(data, slow)
+
+	macd := make([]float64, len(data))
+	for i := range data {
+		macd[i] = fastEMA[i] - slowEMA[i]
+	}
+	signalLine := exponentialAverage(macd, signal)
+
+	histogram := make([]float64, len(data))
+	for i := range data {
+		histogram[i] = macd[i] - signalLine[i]
+	}
+	return MACDResult{MACD: macd, Signal: signalLine, Histogram: histogram}
+}
+
+// calculateStochastic returns the fast %K and its simple average %D. When the high and the low
+// of the window are equal %K is 50.
+func calculateStochastic(candles []Candle, kPeriod, dPeriod int) StochasticResult {
+	k := nanSeries(len(candles))
+	for i := kPeriod - 1; i < len(candles); i++ {
+		high, low := highest(candles, i, kPeriod), lowest(candles, i, kPeriod)
+		if high == low {
+			k[i] = 50
+			continue
+		}
+		k[i] = 100 * (candles[i].C - low) / (high - low)
+	}
+	return StochasticResult{K: k, D: simpleAverage(k, dPeriod)}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ Indicatorer = (*Indicator)(nil)
+
+// Closing prices and RSI(14) values from the StockCharts RSI example. The example rounds
+// the intermediate averages, so the values differ from the exact ones in the first decimal.
+var (
+	referencePrices = []float64{44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08, 45.89, 46.03, 45.61, 46.28, 46.28,
+		46.00, 46.03, 46.41, 46.22, 45.64, 46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57, 43.42, 42.66, 43.13}
+	referenceRSI = []float64{70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38, 54.71, 50.42, 39.99, 41.46,
+		41.87, 45.46, 37.30, 33.08, 37.77}
+)
+
+func assertSeries(t *testing.T, expected, result []float64, delta float64) {
+	t.Helper()
+	if !assert.Len(t, result, len(expected)) {
+		return
+	}
+	for i := range expected {
+		if math.IsNaN(expected[i]) {
+			assert.True(t, math.IsNaN(result[i]), "index %d: got %v, want NaN", i, result[i])
+			continue
+		}
+		assert.InDelta(t, expected[i], result[i], delta, "index %d", i)
+	}
+}
+
+func candlesExchange(candles string) *Exmo {
+	return NewExmo(WithRequester(RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		return []byte(`{"candles":` + candles + `}`), nil
+	})))
+}
+
+func Test_calculateRSI(t *testing.T) {
+	expected := append(nanSeries(14), referenceRSI...)
+
+	assertSeries(t, expected, calculateRSI(referencePrices, 14), 0.1)
+	assertSeries(t, nanSeries(3), calculateRSI([]float64{1, 2, 3}, 3), 0)
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 100}, calculateRSI([]float64{1, 2, 3}, 2), 0)
+}
+
+func Test_calculateMACD(t *testing.T) {
+	data := []float64{1, 2, 3, 4, 5, 6, 7}
+	nan := math.NaN()
+
+	result := calculateMACD(data, 2, 3, 2)
+
+	assertSeries(t, []float64{nan, nan, 0.5, 0.5, 0.5, 0.5, 0.5}, result.MACD, 1e-9)
+	assertSeries(t, []float64{nan, nan, nan, 0.5, 0.5, 0.5, 0.5}, result.Signal, 1e-9)
+	assertSeries(t, []float64{nan, nan, nan, 0, 0, 0, 0}, result.Histogram, 1e-9)
+}
+
+func Test_calculateStochastic(t *testing.T) {
+	candles := []Candle{
+		{H: 10, L: 5, C: 7},
+		{H: 12, L: 6, C: 11},
+		{H: 11, L: 8, C: 9},
+		{H: 9, L: 9, C: 9},
+	}
+	nan := math.NaN()
+
+	result := calculateStochastic(candles, 2, 2)
+
+	assertSeries(t, []float64{nan, 600.0 / 7, 50, 100.0 / 3}, result.K, 1e-9)
+	assertSeries(t, []float64{nan, nan, (600.0/7 + 50) / 2, (50 + 100.0/3) / 2}, result.D, 1e-9)
+	assertSeries(t, []float64{50}, calculateStochastic(candles[3:], 1, 1).K, 0)
+}
+
+func TestIndicator_RSI(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"c":1},{"c":2},{"c":1},{"c":2}]`))
+	nan := math.NaN()
+
+	result, err := indicator.RSI("ADA_BTC", 30, 2, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.NoError(t, err)
+	assertSeries(t, []float64{nan, nan, 50, 75}, result, 1e-9)
+
+	_, err = indicator.RSI("ADA_BTC", 30, 0, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	_, err = NewIndicator(NewExmo(Test())).RSI("BTC_USD", 30, 2, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.Error(t, err)
+}
+
+func TestIndicator_MACD(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"c":1},{"c":2},{"c":3},{"c":4}]`))
+
+	result, err := indicator.MACD("ADA_BTC", 30, 2, 3, 2, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.NoError(t, err)
+	assert.Len(t, result.Histogram, 4)
+
+	_, err = indicator.MACD("ADA_BTC", 30, 3, 2, 2, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	_, err = indicator.MACD("ADA_BTC", 30, 2, 3, 0, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestIndicator_Stochastic(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"h":2,"l":0,"c":1},{"h":4,"l":2,"c":4}]`))
+
+	result, err := indicator.Stochastic("ADA_BTC", 30, 2, 1, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 100}, result.K, 1e-9)
+
+	_, err = indicator.Stochastic("ADA_BTC", 30, 2, 0, time.Unix(1701289470, 0), time.Unix(1701300270, 0))
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestIndicator_oscillatorSeries(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"t":0,"h":2,"l":0,"c":1},{"t":1800000,"h":3,"l":1,"c":2},{"t":3600000,"h":2,"l":0,"c":1},{"t":5400000,"h":3,"l":1,"c":2}]`))
+	from, to := time.Unix(0, 0), time.Unix(7200, 0)
+
+	rsi, err := indicator.RSISeries("ADA_BTC", 30, 2, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 50, 75}, rsi.Values(), 1e-9)
+	assert.Equal(t, time.Unix(3600, 0), rsi[2].Start)
+	assert.Equal(t, time.Unix(5400, 0), rsi[2].End)
+	assert.False(t, rsi[1].Valid)
+	_, err = indicator.RSISeries("ADA_BTC", 30, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	macd, err := indicator.MACDSeries("ADA_BTC", 30, 2, 3, 2, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, macd, 3)
+	assert.Len(t, macd["histogram"], 4)
+	_, err = indicator.MACDSeries("ADA_BTC", 30, 3, 2, 2, from, to)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	stochastic, err := indicator.StochasticSeries("ADA_BTC", 30, 2, 1, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 200.0 / 3, 100.0 / 3, 200.0 / 3}, stochastic["k"].Values(), 1e-9)
+	_, err = indicator.StochasticSeries("ADA_BTC", 30, 0, 1, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	_, err = NewIndicator(NewExmo(Test())).RSISeries("BTC_USD", 30, 2, from, to)
+	assert.Error(t, err)
+}
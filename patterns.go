@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type Pattern string
+
+const (
+	PatternDoji               Pattern = "doji"
+	PatternHammer             Pattern = "hammer"
+	PatternShootingStar       Pattern = "shooting_star"
+	PatternEngulfing          Pattern = "engulfing"
+	PatternHarami             Pattern = "harami"
+	PatternMorningStar        Pattern = "morning_star"
+	PatternEveningStar        Pattern = "evening_star"
+	PatternThreeWhiteSoldiers Pattern = "three_white_soldiers"
+	PatternThreeBlackCrows    Pattern = "three_black_crows"
+)
+
+type Direction int
+
+const (
+	Neutral Direction = iota
+	Bullish
+	Bearish
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Bullish:
+		return "bullish"
+	case Bearish:
+		return "bearish"
+	}
+	return "neutral"
+}
+
+// PatternMatch is a pattern formed by the Candles candles ending at Index. Time is the start
+// of the last candle of the pattern. Strength is between 0 and 1, the closer the candles are
+// to the textbook shape, the higher it is.
+type PatternMatch struct {
+	Pattern   Pattern
+	Time      time.Time
+	Index     int
+	Candles   int
+	Direction Direction
+	Strength  float64
+}
+
+const (
+	// dojiBody is the largest body of a doji relative to its range.
+	dojiBody = 0.1
+	// shadowRatio is the smallest ratio of the long shadow of a hammer or a shooting star to its body.
+	shadowRatio = 2.0
+	// shortShadow is the largest short shadow of a hammer or a shooting star relative to its range.
+	shortShadow = 0.1
+	// starBody is the largest body of the middle candle of a star relative to the body of the first one.
+	starBody = 0.3
+	// trendCandles is the number of candles before a hammer or a shooting star that set the trend it reverses.
+	trendCandles = 3
+)
+
+type patternDetector struct {
+	candles int
+	detect  func(candles []Candle, i int) (Direction, float64, bool)
+}
+
+var patternDetectors = map[Pattern]patternDetector{
+	PatternDoji:               {candles: 1, detect: detectDoji},
+	PatternHammer:             {candles: 1, detect: detectHammer},
+	PatternShootingStar:       {candles: 1, detect: detectShootingStar},
+	PatternEngulfing:          {candles: 2, detect: detectEngulfing},
+	PatternHarami:             {candles: 2, detect: detectHarami},
+	PatternMorningStar:        {candles: 3, detect: detectMorningStar},
+	PatternEveningStar:        {candles: 3, detect: detectEveningStar},
+	PatternThreeWhiteSoldiers: {candles: 3, detect: detectThreeWhiteSoldiers},
+	PatternThreeBlackCrows:    {candles: 3, detect: detectThreeBlackCrows},
+}
+
+var allPatterns = []Pattern{PatternDoji, PatternHammer, PatternShootingStar, PatternEngulfing, PatternHarami,
+	PatternMorningStar, PatternEveningStar, PatternThreeWhiteSoldiers, PatternThreeBlackCrows}
+
+// DetectPatterns finds patterns in candles ordered by time, all the known ones if patterns
+// is empty. The matches are ordered by Index.
+func DetectPatterns(candles []Candle, patterns ...Pattern) ([]PatternMatch, error) {
+	if len(patterns) == 0 {
+		patterns = allPatterns
+	}
+
+	detectors := make([]patternDetector, 0, len(patterns))
+	for _, pattern := range patterns {
+		detector, ok := patternDetectors[pattern]
+		if !ok {
+			return nil, fmt.Errorf("DetectPatterns -> unknown pattern %q: %w", pattern, ErrInvalidParams)
+		}
+		detectors = append(detectors, detector)
+	}
+
+	res := make([]PatternMatch, 0)
+	for i := range candles {
+		for j, detector := range detectors {
+			if i+1 < detector.candles {
+				continue
+			}
+			direction, strength, ok := detector.detect(candles, i)
+			if !ok {
+				continue
+			}
+			res = append(res, PatternMatch{
+				Pattern:   patterns[j],
+				Time:      time.UnixMilli(candles[i].T),
+				Index:     i,
+				Candles:   detector.candles,
+				Direction: direction,
+				Strength:  clamp(strength, 0, 1),
+			})
+		}
+	}
+	return res, nil
+}
+
+// Patterns detects patterns in the candles of pair between from and to.
+func (i *Indicator) Patterns(pair string, limit int, from, to time.Time, patterns ...Pattern) ([]PatternMatch, error) {
+	candles, err := i.GetCandles(pair, limit, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_Patterns -> %w", err)
+	}
+
+	res, err := DetectPatterns(candles, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_Patterns -> %w", err)
+	}
+	return res, nil
+}
+
+func body(candle Candle) float64 {
+	return math.Abs(candle.C - candle.O)
+}
+
+func highLowRange(candle Candle) float64 {
+	return candle.H - candle.L
+}
+
+func upperShadow(candle Candle) float64 {
+	return candle.H - math.Max(candle.O, candle.C)
+}
+
+func lowerShadow(candle Candle) float64 {
+	return math.Min(candle.O, candle.C) - candle.L
+}
+
+func isBullish(candle Candle) bool {
+	return candle.C > candle.O
+}
+
+func isBearish(candle Candle) bool {
+	return candle.C < candle.O
+}
+
+func clamp(value, min, max float64) float64 {
+	return math.Max(min, math.Min(max, value))
+}
+
+// trendBefore tells the direction of the closes of the trendCandles candles before i.
+func trendBefore(candles []Candle, i int) Direction {
+	if i < trendCandles {
+		return Neutral
+	}
+	first, last := candles[i-trendCandles].C, candles[i-1].C
+	switch {
+	case last < first:
+		return Bearish
+	case last > first:
+		return Bullish
+	}
+	return Neutral
+}
+
+func detectDoji(candles []Candle, i int) (Direction, float64, bool) {
+	candle := candles[i]
+	if highLowRange(candle) <= 0 || body(candle) > dojiBody*highLowRange(candle) {
+		return Neutral, 0, false
+	}
+	return Neutral, 1 - body(candle)/(dojiBody*highLowRange(candle)), true
+}
+
+func detectHammer(candles []Candle, i int) (Direction, float64, bool) {
+	candle := candles[i]
+	if trendBefore(candles, i) != Bearish || highLowRange(candle) <= 0 {
+		return Neutral, 0, false
+	}
+	if lowerShadow(candle) < shadowRatio*body(candle) || upperShadow(candle) > shortShadow*highLowRange(candle) {
+		return Neutral, 0, false
+	}
+	return Bullish, lowerShadow(candle) / highLowRange(candle), true
+}
+
+func detectShootingStar(candles []Candle, i int) (Direction, float64, bool) {
+	candle := candles[i]
+	if trendBefore(candles, i) != Bullish || highLowRange(candle) <= 0 {
+		return Neutral, 0, false
+	}
+	if upperShadow(candle) < shadowRatio*body(candle) || lowerShadow(candle) > shortShadow*highLowRange(candle) {
+		return Neutral, 0, false
+	}
+	return Bearish, upperShadow(candle) / highLowRange(candle), true
+}
+
+// detectEngulfing matches a candle whose body covers the opposite colored body before it.
+func detectEngulfing(candles []Candle, i int) (Direction, float64, bool) {
+	prev, cur := candles[i-1], candles[i]
+	if body(cur) <= body(prev) {
+		return Neutral, 0, false
+	}
+
+	strength := 1 - body(prev)/body(cur)
+	switch {
+	case isBearish(prev) && isBullish(cur) && cur.O <= prev.C && cur.C >= prev.O:
+		return Bullish, strength, true
+	case isBullish(prev) && isBearish(cur) && cur.O >= prev.C && cur.C <= prev.O:
+		return Bearish, strength, true
+	}
+	return Neutral, 0, false
+}
+
+// detectHarami matches a candle whose body is inside the opposite colored body before it.
+func detectHarami(candles []Candle, i int) (Direction, float64, bool) {
+	prev, cur := candles[i-1], candles[i]
+	if body(cur) >= body(prev) || math.Max(cur.O, cur.C) > math.Max(prev.O, prev.C) || math.Min(cur.O, cur.C) < math.Min(prev.O, prev.C) {
+		return Neutral, 0, false
+	}
+
+	strength := 1 - body(cur)/body(prev)
+	switch {
+	case isBearish(prev) && isBullish(cur):
+		return Bullish, strength, true
+	case isBullish(prev) && isBearish(cur):
+		return Bearish, strength, true
+	}
+	return Neutral, 0, false
+}
+
+// detectMorningStar matches a long bearish candle, a small body below its close and a bullish
+// candle that closes above the middle of the first body.
+func detectMorningStar(candles []Candle, i int) (Direction, float64, bool) {
+	first, star, last := candles[i-2], candles[i-1], candles[i]
+	if !isBearish(first) || !isBullish(last) || body(star) > starBody*body(first) {
+		return Neutral, 0, false
+	}
+
+	middle := (first.O + first.C) / 2
+	if math.Max(star.O, star.C) > first.C || last.C <= middle {
+		return Neutral, 0, false
+	}
+	return Bullish, (last.C - middle) / (first.O - middle), true
+}
+
+// detectEveningStar matches a long bullish candle, a small body above its close and a bearish
+// candle that closes below the middle of the first body.
+func detectEveningStar(candles []Candle, i int) (Direction, float64, bool) {
+	first, star, last := candles[i-2], candles[i-1], candles[i]
+	if !isBullish(first) || !isBearish(last) || body(star) > starBody*body(first) {
+		return Neutral, 0, false
+	}
+
+	middle := (first.O + first.C) / 2
+	if math.Min(star.O, star.C) < first.C || last.C >= middle {
+		return Neutral, 0, false
+	}
+	return Bearish, (middle - last.C) / (middle - first.O), true
+}
+
+// detectThreeWhiteSoldiers matches three bullish candles with rising closes, each opening
+// within the body before it. The strength is the average share of the bodies in the ranges.
+func detectThreeWhiteSoldiers(candles []Candle, i int) (Direction, float64, bool) {
+	var strength float64
+	for j := i - 2; j <= i; j++ {
+		candle := candles[j]
+		if !isBullish(candle) {
+			return Neutral, 0, false
+		}
+		if j > i-2 {
+			prev := candles[j-1]
+			if candle.C <= prev.C || candle.O < prev.O || candle.O > prev.C {
+				return Neutral, 0, false
+			}
+		}
+		strength += body(candle) / highLowRange(candle) / 3
+	}
+	return Bullish, strength, true
+}
+
+// detectThreeBlackCrows matches three bearish candles with falling closes, each opening
+// within the body before it. The strength is the average share of the bodies in the ranges.
+func detectThreeBlackCrows(candles []Candle, i int) (Direction, float64, bool) {
+	var strength float64
+	for j := i - 2; j <= i; j++ {
+		candle := candles[j]
+		if !isBearish(candle) {
+			return Neutral, 0, false
+		}
+		if j > i-2 {
+			prev := candles[j-1]
+			if candle.C >= prev.C || candle.O > prev.O || candle.O < prev.C {
+				return Neutral, 0, false
+			}
+		}
+		strength += body(candle) / highLowRange(candle) / 3
+	}
+	return Bearish, strength, true
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func trendCandlesFrom(closes ...float64) []Candle {
+	res := make([]Candle, 0, len(closes))
+	for i, price := range closes {
+		open := price + 0.5
+		if i > 0 && price > closes[i-1] {
+			open = price - 0.5
+		}
+		res = append(res, Candle{T: int64(i) * 60000, O: open, C: price, H: open + 0.5, L: price - 0.5})
+	}
+	return res
+}
+
+func withTimestamps(candles ...Candle) []Candle {
+	for i := range candles {
+		candles[i].T = int64(i) * 60000
+	}
+	return candles
+}
+
+func TestDetectPatterns(t *testing.T) {
+	type testData struct {
+		pattern   Pattern
+		candles   []Candle
+		direction Direction
+		strength  float64
+	}
+
+	testCases := []testData{
+		{
+			pattern:   PatternDoji,
+			candles:   withTimestamps(Candle{O: 10, C: 10.05, H: 11, L: 9}),
+			direction: Neutral,
+			strength:  0.75,
+		},
+		{
+			pattern:   PatternHammer,
+			candles:   append(trendCandlesFrom(14, 13, 12), Candle{T: 180000, O: 11, C: 11.2, H: 11.25, L: 10}),
+			direction: Bullish,
+			strength:  0.8,
+		},
+		{
+			pattern:   PatternShootingStar,
+			candles:   append(trendCandlesFrom(10, 11, 12), Candle{T: 180000, O: 13, C: 12.8, H: 14, L: 12.75}),
+			direction: Bearish,
+			strength:  0.8,
+		},
+		{
+			pattern:   PatternEngulfing,
+			candles:   withTimestamps(Candle{O: 10, C: 9, H: 10.5, L: 8.5}, Candle{O: 8.8, C: 10.5, H: 11, L: 8.5}),
+			direction: Bullish,
+			strength:  1 - 1/1.7,
+		},
+		{
+			pattern:   PatternHarami,
+			candles:   withTimestamps(Candle{O: 10, C: 12, H: 12.5, L: 9.5}, Candle{O: 11.5, C: 11, H: 11.6, L: 10.9}),
+			direction: Bearish,
+			strength:  0.75,
+		},
+		{
+			pattern: PatternMorningStar,
+			candles: withTimestamps(Candle{O: 12, C: 10, H: 12.2, L: 9.8}, Candle{O: 9.5, C: 9.4, H: 9.7, L: 9.2},
+				Candle{O: 9.8, C: 11.5, H: 11.6, L: 9.7}),
+			direction: Bullish,
+			strength:  0.5,
+		},
+		{
+			pattern: PatternEveningStar,
+			candles: withTimestamps(Candle{O: 10, C: 12, H: 12.2, L: 9.8}, Candle{O: 12.5, C: 12.6, H: 12.8, L: 12.3},
+				Candle{O: 12.2, C: 10.5, H: 12.3, L: 10.4}),
+			direction: Bearish,
+			strength:  0.5,
+		},
+		{
+			pattern: PatternThreeWhiteSoldiers,
+			candles: withTimestamps(Candle{O: 10, C: 11, H: 11.25, L: 10}, Candle{O: 10.5, C: 11.5, H: 11.5, L: 10.5},
+				Candle{O: 11, C: 12, H: 12, L: 11}),
+			direction: Bullish,
+			strength:  (0.8 + 1 + 1) / 3,
+		},
+		{
+			pattern: PatternThreeBlackCrows,
+			candles: withTimestamps(Candle{O: 12, C: 11, H: 12, L: 11}, Candle{O: 11.5, C: 10.5, H: 11.5, L: 10.5},
+				Candle{O: 11, C: 10, H: 11, L: 10}),
+			direction: Bearish,
+			strength:  1,
+		},
+	}
+
+	for _, tc := range testCases {
+		matches, err := DetectPatterns(tc.candles, tc.pattern)
+
+		assert.NoError(t, err)
+		if assert.Len(t, matches, 1, tc.pattern) {
+			last := len(tc.candles) - 1
+			assert.Equal(t, last, matches[0].Index)
+			assert.Equal(t, time.UnixMilli(tc.candles[last].T), matches[0].Time)
+			assert.Equal(t, tc.direction, matches[0].Direction, tc.pattern)
+			assert.InDelta(t, tc.strength, matches[0].Strength, 1e-9, tc.pattern)
+		}
+	}
+}
+
+func TestDetectPatterns_noMatch(t *testing.T) {
+	// A hammer shape after rising closes is not a reversal.
+	candles := append(trendCandlesFrom(10, 11, 12), Candle{T: 180000, O: 11, C: 11.2, H: 11.25, L: 10})
+	matches, err := DetectPatterns(candles, PatternHammer)
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+
+	matches, err = DetectPatterns(risingCandles(5), PatternEngulfing, PatternHarami, PatternMorningStar)
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+
+	_, err = DetectPatterns(candles, "unknown")
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestIndicator_Patterns(t *testing.T) {
+	exchange := candlesExchange(`[{"t":0,"o":10,"c":9,"h":10.5,"l":8.5,"v":1},{"t":60000,"o":8.8,"c":10.5,"h":11,"l":8.5,"v":1}]`)
+	indicator := NewIndicator(exchange)
+
+	matches, err := indicator.Patterns("BTC_USD", 1, time.Unix(0, 0), time.Unix(120, 0))
+
+	assert.NoError(t, err)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, PatternEngulfing, matches[0].Pattern)
+		assert.Equal(t, Bullish, matches[0].Direction)
+		assert.Equal(t, 2, matches[0].Candles)
+	}
+}
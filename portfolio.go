@@ -0,0 +1,447 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type CostMethod int
+
+const (
+	// FIFO sells the lots bought first.
+	FIFO CostMethod = iota
+	// LIFO sells the lots bought last.
+	LIFO
+	// AverageCost sells at the average cost of all the held lots.
+	AverageCost
+)
+
+// historicalRateWindow is how long before a trade HistoricalRates looks for the last candle.
+const historicalRateWindow = time.Hour
+
+// AssetValuation is the value of the balance of a currency and its PnL in the quote currency
+// of the portfolio. Quantity and Cost are of the lots bought by the trades and still held,
+// the unrealized PnL is their value at the current rates minus their cost.
+type AssetValuation struct {
+	Currency      string
+	Balance       float64
+	Value         float64
+	Quantity      float64
+	Cost          float64
+	RealizedPnL   float64
+	UnrealizedPnL float64
+	// RateDependentPnL is the part of RealizedPnL of the trades valued at the current rates
+	// rather than at the rates of their time, which changes with the rates.
+	RateDependentPnL float64
+	// Unvalued reports that the currency has no conversion path to the quote one, so Value
+	// and the PnL leave out its balance or some of its trades.
+	Unvalued bool
+}
+
+// Portfolio is the valuation of every asset. Unvalued lists the currencies of the assets that
+// could not be valued.
+type Portfolio struct {
+	Quote            string
+	Value            float64
+	RealizedPnL      float64
+	UnrealizedPnL    float64
+	RateDependentPnL float64
+	Assets           []AssetValuation
+	Unvalued         []string
+}
+
+// RateAt returns the amount of to one from was worth at the given time.
+type RateAt func(from, to string, at time.Time) (float64, error)
+
+type ValuationOption func(*ledger)
+
+// WithHistoricalRates values the trades of pairs not quoted in the quote currency at the rates
+// of the time of the trade. A trade is valued at the current rates, like without the option,
+// when rates has no path for it.
+func WithHistoricalRates(rates RateAt) ValuationOption {
+	return func(l *ledger) {
+		l.rates = rates
+	}
+}
+
+type lot struct {
+	quantity  float64
+	cost      float64
+	estimated bool
+}
+
+// ledger keeps the lots of every currency but the quote one and the realized PnL of selling them.
+// Lots and proceeds valued at the current rates are estimated, and so is the PnL they realize.
+type ledger struct {
+	quote         string
+	method        CostMethod
+	graph         *ConversionGraph
+	rates         RateAt
+	lots          map[string][]lot
+	realized      map[string]float64
+	rateDependent map[string]float64
+	unvalued      map[string]bool
+}
+
+// Valuate values the balances of info in quote at the rates of graph, and replays trades to find
+// the cost of the held lots and the realized PnL. Trades in pairs not quoted in quote are valued
+// at the current rates, unless WithHistoricalRates is set, and the PnL they realize is reported
+// as rate dependent. Selling more than the trades bought, like deposited coins, realizes no PnL
+// for the excess. Currencies without a conversion path to quote are reported as unvalued.
+func Valuate(info UserInfo, trades []UserTrade, graph *ConversionGraph, quote string, method CostMethod, opts ...ValuationOption) (Portfolio, error) {
+	if method != FIFO && method != LIFO && method != AverageCost {
+		return Portfolio{}, fmt.Errorf("Valuate -> unknown cost method %d: %w", method, ErrInvalidParams)
+	}
+
+	l := &ledger{quote: quote, method: method, graph: graph, lots: make(map[string][]lot), realized: make(map[string]float64),
+		rateDependent: make(map[string]float64), unvalued: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(l)
+	}
+	sorted := append([]UserTrade(nil), trades...)
+	sortUserTrades(sorted)
+	for _, trade := range sorted {
+		if err := l.trade(trade); err != nil {
+			return Portfolio{}, fmt.Errorf("Valuate -> trade %d: %w", trade.TradeID, err)
+		}
+	}
+
+	balances, err := parseBalances(info)
+	if err != nil {
+		return Portfolio{}, fmt.Errorf("Valuate -> %w", err)
+	}
+
+	currencies := make(map[string]bool)
+	for currency, balance := range balances {
+		if balance != 0 {
+			currencies[currency] = true
+		}
+	}
+	for currency := range l.lots {
+		currencies[currency] = true
+	}
+	for currency := range l.realized {
+		currencies[currency] = true
+	}
+	for currency := range l.unvalued {
+		currencies[currency] = true
+	}
+
+	portfolio := Portfolio{Quote: quote, Assets: make([]AssetValuation, 0, len(currencies)), Unvalued: make([]string, 0)}
+	for _, currency := range sortedKeys(currencies) {
+		asset := AssetValuation{Currency: currency, Balance: balances[currency], RealizedPnL: l.realized[currency],
+			RateDependentPnL: l.rateDependent[currency], Unvalued: l.unvalued[currency]}
+		for _, held := range l.lots[currency] {
+			asset.Quantity += held.quantity
+			asset.Cost += held.cost
+		}
+
+		if asset.Balance != 0 {
+			value, ok, err := l.assetValue(currency, asset.Balance)
+			if err != nil {
+				return Portfolio{}, fmt.Errorf("Valuate -> %w", err)
+			}
+			asset.Value, asset.Unvalued = value, asset.Unvalued || !ok
+		}
+		if asset.Quantity != 0 {
+			heldValue, ok, err := l.assetValue(currency, asset.Quantity)
+			if err != nil {
+				return Portfolio{}, fmt.Errorf("Valuate -> %w", err)
+			}
+			if ok {
+				asset.UnrealizedPnL = heldValue - asset.Cost
+			}
+			asset.Unvalued = asset.Unvalued || !ok
+		}
+
+		portfolio.Value += asset.Value
+		portfolio.RealizedPnL += asset.RealizedPnL
+		portfolio.UnrealizedPnL += asset.UnrealizedPnL
+		portfolio.RateDependentPnL += asset.RateDependentPnL
+		portfolio.Assets = append(portfolio.Assets, asset)
+		if asset.Unvalued {
+			portfolio.Unvalued = append(portfolio.Unvalued, currency)
+		}
+	}
+	return portfolio, nil
+}
+
+// Portfolio values the balances of the account in quote at the ticker rates, with the PnL
+// of the trades of pairs valued at the rates of their time. Without pairs, the trades of every
+// pair of the ticker and the pair settings are replayed, so the PnL of the positions that were
+// sold in full is included.
+func (e *Exmo) Portfolio(quote string, method CostMethod, pairs ...string) (Portfolio, error) {
+	info, err := e.GetUserInfo()
+	if err != nil {
+		return Portfolio{}, fmt.Errorf("Exmo_Portfolio -> %w", err)
+	}
+	ticker, err := e.GetTicker()
+	if err != nil {
+		return Portfolio{}, fmt.Errorf("Exmo_Portfolio -> %w", err)
+	}
+	graph, err := NewConversionGraph(ticker)
+	if err != nil {
+		return Portfolio{}, fmt.Errorf("Exmo_Portfolio -> %w", err)
+	}
+
+	if len(pairs) == 0 {
+		settings, err := e.GetPairSettings()
+		if err != nil {
+			return Portfolio{}, fmt.Errorf("Exmo_Portfolio -> %w", err)
+		}
+		pairs = exchangePairs(ticker, settings)
+	}
+	trades, err := AllUserTrades(e, pairs...)
+	if err != nil {
+		return Portfolio{}, fmt.Errorf("Exmo_Portfolio -> %w", err)
+	}
+
+	portfolio, err := Valuate(info, trades, graph, quote, method, WithHistoricalRates(e.HistoricalRates(graph)))
+	if err != nil {
+		return Portfolio{}, fmt.Errorf("Exmo_Portfolio -> %w", err)
+	}
+	return portfolio, nil
+}
+
+// HistoricalRates returns the rates along the conversion paths of graph at the close of the last
+// minute candle of every pair before the time. The rate has no path when a pair of the path has
+// not been traded in the hour before. Every rate requests the candles of every pair of its path,
+// so a CandleStore or a cache saves repeating them.
+func (e *Exmo) HistoricalRates(graph *ConversionGraph) RateAt {
+	return func(from, to string, at time.Time) (float64, error) {
+		conversion, err := graph.Convert(from, to)
+		if err != nil {
+			return 0, fmt.Errorf("Exmo_HistoricalRates -> %w", err)
+		}
+
+		rate := 1.0
+		for i, pair := range conversion.Pairs {
+			price, err := e.closeAt(pair, at)
+			if err != nil {
+				return 0, fmt.Errorf("Exmo_HistoricalRates -> %w", err)
+			}
+			if base, _, _ := splitPair(pair); base == conversion.Path[i] {
+				rate *= price
+			} else {
+				rate /= price
+			}
+		}
+		return rate, nil
+	}
+}
+
+// closeAt returns the close of the last minute candle of pair that opened before at.
+func (e *Exmo) closeAt(pair string, at time.Time) (float64, error) {
+	history, err := e.GetCandlesHistory(pair, 1, at.Add(-historicalRateWindow), at)
+	if err != nil {
+		return 0, err
+	}
+
+	price := math.NaN()
+	for _, candle := range history.Candles {
+		if candle.T <= at.UnixMilli() && candle.C > 0 {
+			price = candle.C
+		}
+	}
+	if math.IsNaN(price) {
+		return 0, fmt.Errorf("no %s candles before %v: %w", pair, at, ErrNoConversionPath)
+	}
+	return price, nil
+}
+
+// exchangePairs returns the pairs of ticker and settings in alphabetical order.
+func exchangePairs(ticker Ticker, settings PairSettings) []string {
+	pairs := make(map[string]bool, len(ticker)+len(settings))
+	for pair := range ticker {
+		pairs[pair] = true
+	}
+	for pair := range settings {
+		pairs[pair] = true
+	}
+	return sortedKeys(pairs)
+}
+
+// parseBalances sums the available and the reserved balances of every currency.
+func parseBalances(info UserInfo) (map[string]float64, error) {
+	balances := make(map[string]float64)
+	for _, amounts := range []map[string]string{info.Balances, info.Reserved} {
+		for currency, amount := range amounts {
+			value, err := parsePrice(amount)
+			if err != nil {
+				return nil, fmt.Errorf("%s balance: %w", currency, err)
+			}
+			balances[currency] += value
+		}
+	}
+	return balances, nil
+}
+
+func sortUserTrades(trades []UserTrade) {
+	sort.SliceStable(trades, func(i, j int) bool {
+		if trades[i].Date != trades[j].Date {
+			return trades[i].Date < trades[j].Date
+		}
+		return trades[i].TradeID < trades[j].TradeID
+	})
+}
+
+// assetValue converts amount of currency into the quote at the current rates. It is not ok
+// when there is no conversion path.
+func (l *ledger) assetValue(currency string, amount float64) (float64, bool, error) {
+	if currency == l.quote {
+		return amount, true, nil
+	}
+	value, err := l.graph.Value(amount, currency, l.quote)
+	if errors.Is(err, ErrNoConversionPath) {
+		return 0, false, nil
+	}
+	return value, err == nil, err
+}
+
+// tradeValue converts amount of currency into the quote at the rate of the time of a trade
+// in seconds, or estimates it at the current rate when there is no historical one.
+func (l *ledger) tradeValue(currency string, amount float64, date int64) (value float64, estimated bool, err error) {
+	if currency == l.quote {
+		return amount, false, nil
+	}
+	if l.rates != nil {
+		rate, err := l.rates(currency, l.quote, time.Unix(date, 0))
+		if err == nil {
+			return amount * rate, false, nil
+		}
+		if !errors.Is(err, ErrNoConversionPath) {
+			return 0, false, err
+		}
+	}
+	value, err = l.graph.Value(amount, currency, l.quote)
+	return value, true, err
+}
+
+// trade disposes of one currency of the trade and acquires the other one. The commission
+// lowers the acquired quantity, when it is taken in the acquired currency other than the quote,
+// or the proceeds of the disposal otherwise. A trade that cannot be valued in the quote
+// currency disposes of the lots without realizing PnL and acquires none, and both of its
+// currencies are unvalued.
+func (l *ledger) trade(trade UserTrade) error {
+	base, quoteCurrency, ok := splitPair(trade.Pair)
+	if !ok {
+		return fmt.Errorf("invalid pair %q", trade.Pair)
+	}
+	quantity, err := strconv.ParseFloat(trade.Quantity, 64)
+	if err != nil {
+		return err
+	}
+	amount, err := strconv.ParseFloat(trade.Amount, 64)
+	if err != nil {
+		return err
+	}
+	commission, err := parsePrice(trade.CommissionAmount)
+	if err != nil {
+		return err
+	}
+
+	acquired, acquiredQuantity, disposed, disposedQuantity := base, quantity, quoteCurrency, amount
+	if trade.Type == Sell {
+		acquired, acquiredQuantity, disposed, disposedQuantity = quoteCurrency, amount, base, quantity
+	}
+
+	value, estimated, err := l.tradeValue(quoteCurrency, amount, trade.Date)
+	var fee float64
+	if err == nil {
+		if trade.CommissionCurrency == acquired && acquired != l.quote {
+			acquiredQuantity -= commission
+		} else if commission != 0 {
+			var feeEstimated bool
+			fee, feeEstimated, err = l.tradeValue(trade.CommissionCurrency, commission, trade.Date)
+			estimated = estimated || feeEstimated
+		}
+	}
+	if errors.Is(err, ErrNoConversionPath) {
+		l.take(disposed, disposedQuantity)
+		for _, currency := range []string{base, quoteCurrency} {
+			if currency != l.quote {
+				l.unvalued[currency] = true
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if disposed == l.quote {
+		l.acquire(acquired, acquiredQuantity, value+fee, estimated)
+		return nil
+	}
+	l.dispose(disposed, disposedQuantity, value-fee, estimated)
+	l.acquire(acquired, acquiredQuantity, value, estimated)
+	return nil
+}
+
+func (l *ledger) acquire(currency string, quantity, cost float64, estimated bool) {
+	if currency == l.quote || quantity <= 0 {
+		return
+	}
+
+	lots := l.lots[currency]
+	if l.method == AverageCost && len(lots) > 0 {
+		lots[0].quantity += quantity
+		lots[0].cost += cost
+		lots[0].estimated = lots[0].estimated || estimated
+		return
+	}
+	l.lots[currency] = append(lots, lot{quantity: quantity, cost: cost, estimated: estimated})
+}
+
+// dispose sells quantity of currency for proceeds, realizing the PnL of the sold lots.
+func (l *ledger) dispose(currency string, quantity, proceeds float64, estimated bool) {
+	if currency == l.quote || quantity <= 0 {
+		return
+	}
+
+	for _, sold := range l.take(currency, quantity) {
+		pnl := proceeds*sold.quantity/quantity - sold.cost
+		l.realized[currency] += pnl
+		if estimated || sold.estimated {
+			l.rateDependent[currency] += pnl
+		}
+	}
+	if _, ok := l.realized[currency]; !ok {
+		l.realized[currency] = 0
+	}
+}
+
+// take removes quantity of currency from the held lots, in the order of the cost method,
+// and returns the removed parts.
+func (l *ledger) take(currency string, quantity float64) []lot {
+	lots := l.lots[currency]
+	taken := make([]lot, 0)
+	remaining := quantity
+	for remaining > 0 && len(lots) > 0 {
+		i := 0
+		if l.method == LIFO {
+			i = len(lots) - 1
+		}
+
+		sold := lot{quantity: minFloat(remaining, lots[i].quantity), estimated: lots[i].estimated}
+		sold.cost = lots[i].cost * sold.quantity / lots[i].quantity
+		lots[i].quantity -= sold.quantity
+		lots[i].cost -= sold.cost
+		remaining -= sold.quantity
+		taken = append(taken, sold)
+
+		if lots[i].quantity <= 0 {
+			lots = append(lots[:i], lots[i+1:]...)
+		}
+	}
+	if len(lots) == 0 {
+		delete(l.lots, currency)
+	} else {
+		l.lots[currency] = lots
+	}
+	return taken
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var portfolioTicker = Ticker{
+	"BTC_USD": {BuyPrice: "250", SellPrice: "251"},
+	"ETH_BTC": {BuyPrice: "0.06", SellPrice: "0.061"},
+}
+
+var portfolioTrades = []UserTrade{
+	{TradeID: 3, Date: 3, Type: Sell, Pair: "BTC_USD", Quantity: "1", Price: "300", Amount: "300"},
+	{TradeID: 1, Date: 1, Type: Buy, Pair: "BTC_USD", Quantity: "1", Price: "100", Amount: "100"},
+	{TradeID: 2, Date: 2, Type: Buy, Pair: "BTC_USD", Quantity: "1", Price: "200", Amount: "200"},
+}
+
+func findAsset(t *testing.T, portfolio Portfolio, currency string) AssetValuation {
+	t.Helper()
+	for _, asset := range portfolio.Assets {
+		if asset.Currency == currency {
+			return asset
+		}
+	}
+	t.Fatalf("no asset %s in %v", currency, portfolio.Assets)
+	return AssetValuation{}
+}
+
+func TestValuate(t *testing.T) {
+	graph, err := NewConversionGraph(portfolioTicker)
+	assert.NoError(t, err)
+	info := UserInfo{Balances: map[string]string{"BTC": "1", "USD": "500", "ETH": "0"}, Reserved: map[string]string{"USD": "100"}}
+
+	type testData struct {
+		name       string
+		method     CostMethod
+		realized   float64
+		cost       float64
+		unrealized float64
+	}
+
+	testCases := []testData{
+		{name: "FIFO", method: FIFO, realized: 200, cost: 200, unrealized: 50},
+		{name: "LIFO", method: LIFO, realized: 100, cost: 100, unrealized: 150},
+		{name: "average cost", method: AverageCost, realized: 150, cost: 150, unrealized: 100},
+	}
+
+	for _, tc := range testCases {
+		portfolio, err := Valuate(info, portfolioTrades, graph, "USD", tc.method)
+
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, "USD", portfolio.Quote)
+		assert.InDelta(t, 850, portfolio.Value, 1e-9, tc.name)
+		assert.InDelta(t, tc.realized, portfolio.RealizedPnL, 1e-9, tc.name)
+		assert.InDelta(t, tc.unrealized, portfolio.UnrealizedPnL, 1e-9, tc.name)
+		assert.Zero(t, portfolio.RateDependentPnL, tc.name)
+		assert.Empty(t, portfolio.Unvalued, tc.name)
+		if assert.Len(t, portfolio.Assets, 2, tc.name) {
+			btc := findAsset(t, portfolio, "BTC")
+			assert.Equal(t, AssetValuation{Currency: "BTC", Balance: 1, Value: 250, Quantity: 1, Cost: tc.cost,
+				RealizedPnL: tc.realized, UnrealizedPnL: tc.unrealized}, btc, tc.name)
+			assert.Equal(t, AssetValuation{Currency: "USD", Balance: 600, Value: 600}, findAsset(t, portfolio, "USD"), tc.name)
+		}
+	}
+
+	_, err = Valuate(info, portfolioTrades, graph, "USD", CostMethod(10))
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestValuate_commissions(t *testing.T) {
+	graph, err := NewConversionGraph(portfolioTicker)
+	assert.NoError(t, err)
+	trades := []UserTrade{
+		{TradeID: 1, Date: 1, Type: Buy, Pair: "BTC_USD", Quantity: "1", Amount: "100", CommissionAmount: "0.01", CommissionCurrency: "BTC"},
+		{TradeID: 2, Date: 2, Type: Sell, Pair: "BTC_USD", Quantity: "0.5", Amount: "100", CommissionAmount: "1", CommissionCurrency: "USD"},
+	}
+
+	portfolio, err := Valuate(UserInfo{}, trades, graph, "USD", FIFO)
+
+	assert.NoError(t, err)
+	btc := findAsset(t, portfolio, "BTC")
+	// 0.99 BTC cost 100, half of a BTC of them is sold for 99 USD after the commission.
+	assert.InDelta(t, 0.49, btc.Quantity, 1e-9)
+	assert.InDelta(t, 100*0.49/0.99, btc.Cost, 1e-9)
+	assert.InDelta(t, 99-100*0.5/0.99, btc.RealizedPnL, 1e-9)
+	assert.InDelta(t, 0.49*250-100*0.49/0.99, btc.UnrealizedPnL, 1e-9)
+}
+
+func TestValuate_crossPair(t *testing.T) {
+	graph, err := NewConversionGraph(portfolioTicker)
+	assert.NoError(t, err)
+	trades := []UserTrade{
+		{TradeID: 1, Date: 1, Type: Buy, Pair: "BTC_USD", Quantity: "1", Amount: "100"},
+		{TradeID: 2, Date: 2, Type: Buy, Pair: "ETH_BTC", Quantity: "10", Amount: "0.5"},
+	}
+
+	portfolio, err := Valuate(UserInfo{}, trades, graph, "USD", FIFO)
+
+	assert.NoError(t, err)
+	btc, eth := findAsset(t, portfolio, "BTC"), findAsset(t, portfolio, "ETH")
+	// Paying 0.5 BTC, worth 125 USD now, for ETH realizes the gain of the BTC.
+	assert.InDelta(t, 75, btc.RealizedPnL, 1e-9)
+	assert.InDelta(t, 75, btc.RateDependentPnL, 1e-9)
+	assert.InDelta(t, 75, portfolio.RateDependentPnL, 1e-9)
+	assert.InDelta(t, 50, btc.Cost, 1e-9)
+	assert.InDelta(t, 125, eth.Cost, 1e-9)
+	assert.InDelta(t, 10*0.06*250-125, eth.UnrealizedPnL, 1e-9)
+}
+
+func TestValuate_historicalRates(t *testing.T) {
+	graph, err := NewConversionGraph(portfolioTicker)
+	assert.NoError(t, err)
+	trades := []UserTrade{
+		{TradeID: 1, Date: 1, Type: Buy, Pair: "BTC_USD", Quantity: "1", Amount: "100"},
+		{TradeID: 2, Date: 2, Type: Buy, Pair: "ETH_BTC", Quantity: "10", Amount: "0.5"},
+	}
+	rates := func(from, to string, at time.Time) (float64, error) {
+		assert.Equal(t, "BTC", from)
+		assert.Equal(t, "USD", to)
+		assert.Equal(t, time.Unix(2, 0), at)
+		return 200, nil
+	}
+
+	portfolio, err := Valuate(UserInfo{}, trades, graph, "USD", FIFO, WithHistoricalRates(rates))
+
+	assert.NoError(t, err)
+	btc, eth := findAsset(t, portfolio, "BTC"), findAsset(t, portfolio, "ETH")
+	// The 0.5 BTC were worth 100 USD when they were paid for the ETH.
+	assert.InDelta(t, 50, btc.RealizedPnL, 1e-9)
+	assert.Zero(t, portfolio.RateDependentPnL)
+	assert.InDelta(t, 100, eth.Cost, 1e-9)
+	assert.InDelta(t, 10*0.06*250-100, eth.UnrealizedPnL, 1e-9)
+
+	portfolio, err = Valuate(UserInfo{}, trades, graph, "USD", FIFO, WithHistoricalRates(func(from, to string, at time.Time) (float64, error) {
+		return 0, ErrNoConversionPath
+	}))
+	assert.NoError(t, err)
+	assert.InDelta(t, 75, portfolio.RateDependentPnL, 1e-9)
+
+	_, err = Valuate(UserInfo{}, trades, graph, "USD", FIFO, WithHistoricalRates(func(from, to string, at time.Time) (float64, error) {
+		return 0, errors.New("connection refused")
+	}))
+	assert.Error(t, err)
+}
+
+func TestValuate_unvalued(t *testing.T) {
+	graph, err := NewConversionGraph(portfolioTicker)
+	assert.NoError(t, err)
+	info := UserInfo{Balances: map[string]string{"BTC": "0.5", "DOGE": "5"}}
+	trades := []UserTrade{
+		{TradeID: 1, Date: 1, Type: Buy, Pair: "BTC_USD", Quantity: "1", Amount: "100"},
+		{TradeID: 2, Date: 2, Type: Buy, Pair: "DOGE_EUR", Quantity: "5", Amount: "10"},
+		{TradeID: 3, Date: 3, Type: Sell, Pair: "BTC_EUR", Quantity: "0.5", Amount: "20"},
+	}
+
+	portfolio, err := Valuate(info, trades, graph, "USD", FIFO)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BTC", "DOGE", "EUR"}, portfolio.Unvalued)
+	assert.InDelta(t, 125, portfolio.Value, 1e-9)
+	// Selling BTC for EUR realizes nothing and leaves the other half of the lot.
+	assert.Equal(t, AssetValuation{Currency: "BTC", Balance: 0.5, Value: 125, Quantity: 0.5, Cost: 50, UnrealizedPnL: 75,
+		Unvalued: true}, findAsset(t, portfolio, "BTC"))
+	assert.Equal(t, AssetValuation{Currency: "DOGE", Balance: 5, Unvalued: true}, findAsset(t, portfolio, "DOGE"))
+	assert.Equal(t, AssetValuation{Currency: "EUR", Unvalued: true}, findAsset(t, portfolio, "EUR"))
+}
+
+func TestExmo_HistoricalRates(t *testing.T) {
+	graph, err := NewConversionGraph(portfolioTicker)
+	assert.NoError(t, err)
+	at := time.Unix(1700000000, 0)
+	var urls []string
+	exmo := NewExmo(WithRequester(RequesterFunc(func(method string, rawURL string, body io.Reader) ([]byte, error) {
+		urls = append(urls, rawURL)
+		u, _ := url.Parse(rawURL)
+		if u.Query().Get("to") != "1700000000" {
+			return []byte(`{"candles":[]}`), nil
+		}
+		switch u.Query().Get("symbol") {
+		case "ETH_BTC":
+			return []byte(`{"candles":[{"t":1699999880000,"c":0.05},{"t":1700000000000,"c":0.04},{"t":1700000060000,"c":1}]}`), nil
+		case "BTC_USD":
+			return []byte(`{"candles":[{"t":1699999940000,"c":200}]}`), nil
+		}
+		return []byte(`{"candles":[]}`), nil
+	})))
+	rates := exmo.HistoricalRates(graph)
+
+	rate, err := rates("ETH", "USD", at)
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 8, rate, 1e-9)
+	assert.Equal(t, "https://api.exmo.com/v1.1/candles_history?symbol=ETH_BTC&resolution=1&from=1699996400&to=1700000000", urls[0])
+
+	rate, err = rates("USD", "ETH", at)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.125, rate, 1e-9)
+
+	_, err = rates("ETH", "USD", at.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrNoConversionPath)
+	_, err = rates("DOGE", "USD", at)
+	assert.ErrorIs(t, err, ErrNoConversionPath)
+}
+
+func TestExmo_Portfolio(t *testing.T) {
+	var pairs []string
+	exmo := NewExmo(
+		WithRequester(RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+			if strings.HasSuffix(url, pairSettings) {
+				return []byte(`{"BTC_USD":{},"XRP_USD":{},"DOGE_BTC":{}}`), nil
+			}
+			return []byte(`{"BTC_USD":{"buy_price":"250","sell_price":"251"},"ETH_BTC":{"buy_price":"0.06","sell_price":"0.061"},` +
+				`"XRP_USD":{"buy_price":"0.5","sell_price":"0.51"}}`), nil
+		})),
+		WithAuthRequester(RequesterFunc(func(method string, rawURL string, body io.Reader) ([]byte, error) {
+			if strings.HasSuffix(rawURL, userInfo) {
+				return []byte(`{"balances":{"BTC":"2","USD":"10","XRP":"0"}}`), nil
+			}
+			data, _ := io.ReadAll(body)
+			form, _ := url.ParseQuery(string(data))
+			pairs = append(pairs, form.Get("pair"))
+			return []byte(`{"BTC_USD":[{"trade_id":1,"date":1,"type":"buy","pair":"BTC_USD","quantity":"2","amount":"400"}],` +
+				`"XRP_USD":[{"trade_id":2,"date":2,"type":"buy","pair":"XRP_USD","quantity":"10","amount":"4"},` +
+				`{"trade_id":3,"date":3,"type":"sell","pair":"XRP_USD","quantity":"10","amount":"5"}]}`), nil
+		})),
+	)
+
+	portfolio, err := exmo.Portfolio("USD", FIFO, "BTC_USD")
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 510, portfolio.Value, 1e-9)
+	assert.InDelta(t, 100, portfolio.UnrealizedPnL, 1e-9)
+	assert.Zero(t, portfolio.RealizedPnL)
+
+	// Without pairs, the trades of every pair are replayed, with those of the sold XRP.
+	pairs = nil
+	portfolio, err = exmo.Portfolio("USD", FIFO)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BTC_USD,DOGE_BTC,ETH_BTC,XRP_USD"}, pairs)
+	assert.InDelta(t, 100, portfolio.UnrealizedPnL, 1e-9)
+	assert.InDelta(t, 1, portfolio.RealizedPnL, 1e-9)
+	assert.InDelta(t, 1, findAsset(t, portfolio, "XRP").RealizedPnL, 1e-9)
+}
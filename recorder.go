@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+type RecorderMode int
+
+const (
+	// ModeRecord always calls the wrapped requester and appends every response to the cassette.
+	ModeRecord RecorderMode = iota
+	// ModeReplay serves responses from the cassette only and never touches the network.
+	ModeReplay
+	// ModeRecordMissing serves known requests from the cassette and records the unknown ones.
+	ModeRecordMissing
+)
+
+var ErrInteractionNotFound = errors.New("interaction not found in cassette")
+
+type Interaction struct {
+	Method   string `json:"method"`
+	URL      string `json:"url"`
+	Body     string `json:"body,omitempty"`
+	Response string `json:"response"`
+}
+
+type Recorder struct {
+	requester    Requester
+	path         string
+	mode         RecorderMode
+	mu           sync.Mutex
+	interactions map[string]Interaction
+}
+
+func NewRecorder(requester Requester, path string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{requester: requester, path: path, mode: mode, interactions: make(map[string]Interaction)}
+	if mode != ModeReplay && requester == nil {
+		return nil, errors.New("Recorder_NewRecorder -> requester is required for recording")
+	}
+
+	if err := r.load(); err != nil {
+		return nil, fmt.Errorf("Recorder_NewRecorder -> %w", err)
+	}
+	return r, nil
+}
+
+func NewReplayer(path string) (*Recorder, error) {
+	return NewRecorder(nil, path, ModeReplay)
+}
+
+func (r *Recorder) load() error {
+	file, err := os.Open(r.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && r.mode != ModeReplay {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(line, &interaction); err != nil {
+			return err
+		}
+		r.interactions[requestKey(interaction.Method, interaction.URL, interaction.Body)] = interaction
+	}
+	return scanner.Err()
+}
+
+func (r *Recorder) GetRequest(method string, url string, body io.Reader) ([]byte, error) {
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("Recorder_GetRequest -> %w", err)
+		}
+	}
+	key := requestKey(method, url, string(bodyBytes))
+
+	if r.mode != ModeRecord {
+		r.mu.Lock()
+		interaction, ok := r.interactions[key]
+		r.mu.Unlock()
+		if ok {
+			return []byte(interaction.Response), nil
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("Recorder_GetRequest -> %s %s: %w", method, url, ErrInteractionNotFound)
+		}
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(bodyBytes)
+	}
+	data, err := r.requester.GetRequest(method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("Recorder_GetRequest -> %w", err)
+	}
+
+	interaction := Interaction{Method: method, URL: url, Body: string(bodyBytes), Response: string(data)}
+	if err := r.save(key, interaction); err != nil {
+		return nil, fmt.Errorf("Recorder_GetRequest -> %w", err)
+	}
+	return data, nil
+}
+
+func (r *Recorder) save(key string, interaction Interaction) error {
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	r.interactions[key] = interaction
+	return nil
+}
+
+// requestKey normalizes a request so that equivalent requests share a key:
+// the method is upper-cased and query and form parameters are sorted.
+func requestKey(method, rawURL, body string) string {
+	normalizedURL := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		u.RawQuery = normalizeQuery(u.RawQuery)
+		normalizedURL = u.String()
+	}
+	return strings.ToUpper(method) + " " + normalizedURL + " " + normalizeQuery(body)
+}
+
+func normalizeQuery(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return query
+	}
+	return values.Encode()
+}
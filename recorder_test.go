@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type countingRequester struct {
+	calls int
+}
+
+func (c *countingRequester) GetRequest(method string, url string, body io.Reader) ([]byte, error) {
+	c.calls++
+	if url == "https://api.exmo.com/v1.1/fail" {
+		return nil, errors.New("request failed")
+	}
+	return []byte(`{"url":"` + url + `"}`), nil
+}
+
+func TestNewRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+	if _, err := NewRecorder(nil, path, ModeRecord); err == nil {
+		t.Errorf("expected error for recording without requester, got nil")
+	}
+	if _, err := NewReplayer(path); err == nil {
+		t.Errorf("expected error for replaying a missing cassette, got nil")
+	}
+	if _, err := NewRecorder(&countingRequester{}, path, ModeRecordMissing); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRecorder_GetRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	requester := &countingRequester{}
+
+	recorder, err := NewRecorder(requester, path, ModeRecord)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorded, err := recorder.GetRequest("POST", "https://api.exmo.com/v1.1/trades", strings.NewReader("pair=ADA_BTC&limit=30"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := recorder.GetRequest("POST", "https://api.exmo.com/v1.1/fail", nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replayed, err := replayer.GetRequest("post", "https://api.exmo.com/v1.1/trades", strings.NewReader("limit=30&pair=ADA_BTC"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if string(replayed) != string(recorded) {
+		t.Errorf("unexpected result: got %s, want %s", replayed, recorded)
+	}
+	if _, err := replayer.GetRequest("POST", "https://api.exmo.com/v1.1/fail", nil); !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("unexpected error: got %v, want %v", err, ErrInteractionNotFound)
+	}
+
+	missing, err := NewRecorder(requester, path, ModeRecordMissing)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls := requester.calls
+	if _, err := missing.GetRequest("POST", "https://api.exmo.com/v1.1/trades", strings.NewReader("pair=ADA_BTC&limit=30")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := missing.GetRequest("POST", "https://api.exmo.com/v1.1/ticker", nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if requester.calls != calls+1 {
+		t.Errorf("unexpected calls: got %v, want %v", requester.calls, calls+1)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("unexpected cassette size: got %v lines, want 2", lines)
+	}
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ValueLine is the output line of the indicators that calculate a single series.
+	ValueLine = "value"
+)
+
+var (
+	ErrUnknownIndicator = errors.New("unknown indicator")
+	ErrInvalidSpec      = errors.New("invalid indicator spec")
+)
+
+type ParamType int
+
+const (
+	ParamInt ParamType = iota
+	ParamFloat
+)
+
+type Param struct {
+	Name    string
+	Type    ParamType
+	Default float64
+	// Min is the exclusive lower bound of the parameter.
+	Min float64
+}
+
+// Output holds the lines calculated by an indicator by their names.
+// Indicators with a single series put it into ValueLine.
+type Output map[string][]float64
+
+type Definition struct {
+	Name      string
+	Params    []Param
+	Calculate func(candles []Candle, source PriceSource, args []float64) (Output, error)
+}
+
+// Call is a parsed indicator spec: the definition and the values of all its parameters.
+type Call struct {
+	Definition Definition
+	Args       []float64
+}
+
+func (c Call) Calculate(candles []Candle, source PriceSource) (Output, error) {
+	return c.Definition.Calculate(candles, source, c.Args)
+}
+
+func (c Call) String() string {
+	args := make([]string, 0, len(c.Args))
+	for _, arg := range c.Args {
+		args = append(args, strconv.FormatFloat(arg, 'f', -1, 64))
+	}
+	return c.Definition.Name + "(" + strings.Join(args, ",") + ")"
+}
+
+type Registry struct {
+	mu          sync.RWMutex
+	definitions map[string]Definition
+}
+
+// NewRegistry returns a registry with all the indicators of the package registered.
+func NewRegistry() *Registry {
+	r := &Registry{definitions: make(map[string]Definition)}
+	for _, definition := range builtinDefinitions() {
+		r.definitions[definition.Name] = definition
+	}
+	return r
+}
+
+// Register adds the definition or replaces the one with the same name.
+func (r *Registry) Register(definition Definition) error {
+	if !validName(definition.Name) {
+		return fmt.Errorf("Registry_Register -> name %q: %w", definition.Name, ErrInvalidSpec)
+	}
+	if definition.Calculate == nil {
+		return fmt.Errorf("Registry_Register -> %s has no calculate function: %w", definition.Name, ErrInvalidSpec)
+	}
+	for _, param := range definition.Params {
+		if err := param.validate(param.Default); err != nil {
+			return fmt.Errorf("Registry_Register -> %s default: %w", definition.Name, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.definitions[definition.Name] = definition
+	return nil
+}
+
+// clone copies the registry with definitions added. They are not validated, so they must be
+// built by the package.
+func (r *Registry) clone(definitions ...Definition) *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := &Registry{definitions: make(map[string]Definition, len(r.definitions)+len(definitions))}
+	for name, definition := range r.definitions {
+		res.definitions[name] = definition
+	}
+	for _, definition := range definitions {
+		res.definitions[definition.Name] = definition
+	}
+	return res
+}
+
+func (r *Registry) Lookup(name string) (Definition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definition, ok := r.definitions[name]
+	if !ok {
+		return Definition{}, fmt.Errorf("Registry_Lookup -> %q: %w", name, ErrUnknownIndicator)
+	}
+	return definition, nil
+}
+
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]string, 0, len(r.definitions))
+	for name := range r.definitions {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Parse parses a spec like "rsi(14)", "macd(12,26,9)" or "obv". Omitted trailing parameters
+// take their default values.
+func (r *Registry) Parse(spec string) (Call, error) {
+	spec = strings.TrimSpace(spec)
+	name, argsStr := spec, ""
+	if open := strings.IndexByte(spec, '('); open >= 0 {
+		if !strings.HasSuffix(spec, ")") {
+			return Call{}, fmt.Errorf("Registry_Parse -> %q: missing closing parenthesis: %w", spec, ErrInvalidSpec)
+		}
+		name, argsStr = strings.TrimSpace(spec[:open]), strings.TrimSpace(spec[open+1:len(spec)-1])
+	}
+
+	definition, err := r.Lookup(strings.ToLower(name))
+	if err != nil {
+		return Call{}, fmt.Errorf("Registry_Parse -> %w", err)
+	}
+
+	var values []float64
+	if argsStr != "" {
+		for _, arg := range strings.Split(argsStr, ",") {
+			value, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+			if err != nil {
+				return Call{}, fmt.Errorf("Registry_Parse -> %q: %v: %w", spec, err, ErrInvalidSpec)
+			}
+			values = append(values, value)
+		}
+	}
+	args, err := definition.args(values)
+	if err != nil {
+		return Call{}, fmt.Errorf("Registry_Parse -> %q: %w", spec, err)
+	}
+	return Call{Definition: definition, Args: args}, nil
+}
+
+// Calculate calculates the indicator called name with args, completed by the defaults.
+func (r *Registry) Calculate(name string, candles []Candle, source PriceSource, args ...float64) (Output, error) {
+	definition, err := r.Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("Registry_Calculate -> %w", err)
+	}
+
+	fullArgs, err := definition.args(args)
+	if err != nil {
+		return nil, fmt.Errorf("Registry_Calculate -> %s: %w", name, err)
+	}
+	return definition.Calculate(candles, source, fullArgs)
+}
+
+// args validates values and completes them with the defaults of the omitted parameters.
+func (d Definition) args(values []float64) ([]float64, error) {
+	if len(values) > len(d.Params) {
+		return nil, fmt.Errorf("%s takes %d parameters, got %d: %w", d.Name, len(d.Params), len(values), ErrInvalidSpec)
+	}
+
+	res := make([]float64, 0, len(d.Params))
+	for i, param := range d.Params {
+		value := param.Default
+		if i < len(values) {
+			value = values[i]
+		}
+		if err := param.validate(value); err != nil {
+			return nil, err
+		}
+		res = append(res, value)
+	}
+	return res, nil
+}
+
+func (p Param) validate(value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("%s must be finite: %w", p.Name, ErrInvalidSpec)
+	}
+	if p.Type == ParamInt && value != math.Trunc(value) {
+		return fmt.Errorf("%s must be an integer, got %v: %w", p.Name, value, ErrInvalidSpec)
+	}
+	if value <= p.Min {
+		return fmt.Errorf("%s must be greater than %v, got %v: %w", p.Name, p.Min, value, ErrInvalidSpec)
+	}
+	return nil
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func periodParam(name string, defaultValue float64) Param {
+	return Param{Name: name, Type: ParamInt, Default: defaultValue}
+}
+
+func valueOutput(values []float64) Output {
+	return Output{ValueLine: values}
+}
+
+func movingAverageDefinition(name string, movingAverage MovingAverage, defaultPeriod float64) Definition {
+	return Definition{
+		Name:   name,
+		Params: []Param{periodParam("period", defaultPeriod)},
+		Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+			return valueOutput(movingAverage(Prices(candles, source), int(args[0]))), nil
+		},
+	}
+}
+
+func builtinDefinitions() []Definition {
+	res := []Definition{
+		{
+			Name:   "rsi",
+			Params: []Param{periodParam("period", 14)},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return valueOutput(calculateRSI(Prices(candles, source), int(args[0]))), nil
+			},
+		},
+		{
+			Name:   "macd",
+			Params: []Param{periodParam("fast", 12), periodParam("slow", 26), periodParam("signal", 9)},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				if args[0] >= args[1] {
+					return nil, fmt.Errorf("macd: fast period must be less than slow: %w", ErrInvalidParams)
+				}
+				macd := calculateMACD(Prices(candles, source), int(args[0]), int(args[1]), int(args[2]))
+				return Output{"macd": macd.MACD, "signal": macd.Signal, "histogram": macd.Histogram}, nil
+			},
+		},
+		{
+			Name:   "stoch",
+			Params: []Param{periodParam("k", 14), periodParam("d", 3)},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				stochastic := calculateStochastic(candles, int(args[0]), int(args[1]))
+				return Output{"k": stochastic.K, "d": stochastic.D}, nil
+			},
+		},
+		{
+			Name:   "bbands",
+			Params: []Param{periodParam("period", 20), {Name: "k", Type: ParamFloat, Default: 2}},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return bandsOutput(calculateBollingerBands(Prices(candles, source), int(args[0]), args[1])), nil
+			},
+		},
+		{
+			Name:   "atr",
+			Params: []Param{periodParam("period", 14)},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return valueOutput(calculateATR(candles, int(args[0]))), nil
+			},
+		},
+		{
+			Name:   "keltner",
+			Params: []Param{periodParam("period", 20), periodParam("atr_period", 10), {Name: "multiplier", Type: ParamFloat, Default: 2}},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return bandsOutput(calculateKeltnerChannels(candles, Prices(candles, source), int(args[0]), int(args[1]), args[2])), nil
+			},
+		},
+		{
+			Name:   "donchian",
+			Params: []Param{periodParam("period", 20)},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return bandsOutput(calculateDonchianChannels(candles, int(args[0]))), nil
+			},
+		},
+		{
+			Name: "vwap",
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return valueOutput(calculateVWAP(candles, time.UTC)), nil
+			},
+		},
+		{
+			Name:   "rolling_vwap",
+			Params: []Param{periodParam("period", 20)},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return valueOutput(calculateRollingVWAP(candles, int(args[0]))), nil
+			},
+		},
+		{
+			Name: "obv",
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return valueOutput(calculateOBV(candles)), nil
+			},
+		},
+		{
+			Name:   "mfi",
+			Params: []Param{periodParam("period", 14)},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return valueOutput(calculateMFI(candles, int(args[0]))), nil
+			},
+		},
+		{
+			Name: "ad",
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				return valueOutput(calculateAccumulationDistribution(candles)), nil
+			},
+		},
+		{
+			Name:   "dmi",
+			Params: []Param{periodParam("period", 14)},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				dmi := calculateDMI(candles, int(args[0]))
+				return Output{"plus_di": dmi.PlusDI, "minus_di": dmi.MinusDI, "adx": dmi.ADX}, nil
+			},
+		},
+		{
+			Name:   "psar",
+			Params: []Param{{Name: "step", Type: ParamFloat, Default: 0.02}, {Name: "max_step", Type: ParamFloat, Default: 0.2}},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				if args[1] < args[0] {
+					return nil, fmt.Errorf("psar: step must not be above max step: %w", ErrInvalidParams)
+				}
+				return valueOutput(calculateParabolicSAR(candles, args[0], args[1])), nil
+			},
+		},
+		{
+			Name:   "ichimoku",
+			Params: []Param{periodParam("tenkan", 9), periodParam("kijun", 26), periodParam("senkou_b", 52)},
+			Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+				ichimoku := calculateIchimoku(candles, int(args[0]), int(args[1]), int(args[2]), int(args[1]))
+				return Output{
+					"tenkan":   ichimoku.Tenkan,
+					"kijun":    ichimoku.Kijun,
+					"senkou_a": ichimoku.SenkouA,
+					"senkou_b": ichimoku.SenkouB,
+					"chikou":   ichimoku.Chikou,
+				}, nil
+			},
+		},
+	}
+
+	for name, movingAverage := range movingAverages {
+		res = append(res, movingAverageDefinition(name, movingAverage, 20))
+	}
+	return res
+}
+
+func bandsOutput(bands Bands) Output {
+	return Output{"upper": bands.Upper, "middle": bands.Middle, "lower": bands.Lower}
+}
+
+func seriesBands(output SeriesOutput) Bands {
+	return Bands{Upper: output["upper"].Values(), Middle: output["middle"].Values(), Lower: output["lower"].Values()}
+}
+
+// WithRegistry replaces the registry of the indicator. WithSMA and WithEMA do not change it,
+// whatever the order of the options, they apply to a copy of it.
+func WithRegistry(registry *Registry) IndicatorOption {
+	return func(i *Indicator) {
+		i.registry = registry
+	}
+}
+
+// Evaluate calculates the indicator described by spec, like "macd(12,26,9)", over the candles of pair.
+func (i *Indicator) Evaluate(spec string, pair string, limit int, from, to time.Time) (Output, error) {
+	res, err := i.EvaluateSeries(spec, pair, limit, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_Evaluate -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+func (i *Indicator) EvaluateCandles(spec string, candles []Candle) (Output, error) {
+	call, err := i.registry.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_EvaluateCandles -> %w", err)
+	}
+
+	output, err := call.Calculate(candles, i.source)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_EvaluateCandles -> %w", err)
+	}
+	return output, nil
+}
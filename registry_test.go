@@ -0,0 +1,157 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRegistry(t *testing.T) {
+	result := NewRegistry()
+
+	for _, name := range []string{"sma", "ema", "wma", "rsi", "macd", "stoch", "bbands", "atr", "keltner", "donchian",
+		"vwap", "rolling_vwap", "obv", "mfi", "ad", "dmi", "psar", "ichimoku"} {
+		_, err := result.Lookup(name)
+		assert.NoError(t, err, name)
+	}
+	assert.Contains(t, result.Names(), "kama")
+	assert.NotContains(t, result.Names(), periodsSMA)
+	assert.NotContains(t, result.Names(), periodsEMA)
+
+	_, err := result.Parse("periods_sma(3)")
+	assert.ErrorIs(t, err, ErrUnknownIndicator)
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+	double := Definition{
+		Name:   "double",
+		Params: []Param{{Name: "factor", Type: ParamFloat, Default: 2}},
+		Calculate: func(candles []Candle, source PriceSource, args []float64) (Output, error) {
+			res := Prices(candles, source)
+			for i := range res {
+				res[i] *= args[0]
+			}
+			return valueOutput(res), nil
+		},
+	}
+
+	assert.NoError(t, registry.Register(double))
+	result, err := registry.Calculate("double", testCandles, SourceClose)
+	assert.NoError(t, err)
+	assert.Equal(t, Output{ValueLine: {6, 10, 8}}, result)
+
+	double.Name = "Double"
+	assert.ErrorIs(t, registry.Register(double), ErrInvalidSpec)
+
+	double.Name, double.Params[0].Default = "double", -1
+	assert.ErrorIs(t, registry.Register(double), ErrInvalidSpec)
+
+	assert.ErrorIs(t, registry.Register(Definition{Name: "empty"}), ErrInvalidSpec)
+}
+
+func TestRegistry_Parse(t *testing.T) {
+	registry := NewRegistry()
+
+	type testData struct {
+		spec        string
+		expected    string
+		expectedErr error
+	}
+
+	testCases := []testData{
+		{spec: "rsi(14)", expected: "rsi(14)"},
+		{spec: " MACD( 12, 26 ,9 ) ", expected: "macd(12,26,9)"},
+		{spec: "macd(5)", expected: "macd(5,26,9)"},
+		{spec: "obv", expected: "obv()"},
+		{spec: "bbands(20, 2.5)", expected: "bbands(20,2.5)"},
+		{spec: "rsi(14.5)", expectedErr: ErrInvalidSpec},
+		{spec: "rsi(0)", expectedErr: ErrInvalidSpec},
+		{spec: "rsi(14", expectedErr: ErrInvalidSpec},
+		{spec: "rsi(a)", expectedErr: ErrInvalidSpec},
+		{spec: "rsi(14,2)", expectedErr: ErrInvalidSpec},
+		{spec: "unknown(1)", expectedErr: ErrUnknownIndicator},
+	}
+
+	for _, tc := range testCases {
+		result, err := registry.Parse(tc.spec)
+		if tc.expectedErr != nil {
+			assert.ErrorIs(t, err, tc.expectedErr, tc.spec)
+			continue
+		}
+		assert.NoError(t, err, tc.spec)
+		assert.Equal(t, tc.expected, result.String())
+	}
+}
+
+func TestRegistry_Calculate(t *testing.T) {
+	registry := NewRegistry()
+
+	result, err := registry.Calculate("sma", testCandles, SourceClose, 2)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 4, 4.5}, result[ValueLine], 1e-9)
+
+	result, err = registry.Calculate("donchian", testCandles, SourceClose, 2)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 6, 8}, result["upper"], 0)
+
+	_, err = registry.Calculate("macd", testCandles, SourceClose, 26, 12)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	_, err = registry.Calculate("unknown", testCandles, SourceClose)
+	assert.ErrorIs(t, err, ErrUnknownIndicator)
+}
+
+func TestWithRegistry(t *testing.T) {
+	registry := NewRegistry()
+
+	result := NewIndicator(NewExmo(Test()), WithRegistry(registry))
+
+	assert.Equal(t, registry, result.registry)
+
+	wma, err := MovingAverageByName("wma")
+	assert.NoError(t, err)
+	for _, opts := range [][]IndicatorOption{
+		{WithRegistry(registry), WithSMA(wma)},
+		{WithSMA(wma), WithRegistry(registry)},
+	} {
+		result = NewIndicator(NewExmo(Test()), opts...)
+		assert.NotSame(t, registry, result.registry)
+
+		output, err := result.registry.Calculate("sma", testCandles, SourceClose, 2)
+		assert.NoError(t, err)
+		assertSeries(t, []float64{math.NaN(), 13.0 / 3, 13.0 / 3}, output[ValueLine], 1e-9)
+	}
+
+	output, err := registry.Calculate("sma", testCandles, SourceClose, 2)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 4, 4.5}, output[ValueLine], 1e-9)
+}
+
+func TestIndicator_Evaluate(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"c":1},{"c":2},{"c":1},{"c":2}]`))
+	from, to := time.Unix(1701289470, 0), time.Unix(1701300270, 0)
+
+	result, err := indicator.Evaluate("rsi(2)", "ADA_BTC", 30, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 50, 75}, result[ValueLine], 1e-9)
+
+	_, err = indicator.Evaluate("rsi(x)", "ADA_BTC", 30, from, to)
+	assert.ErrorIs(t, err, ErrInvalidSpec)
+
+	_, err = NewIndicator(NewExmo(Test())).Evaluate("rsi", "BTC_USD", 30, from, to)
+	assert.Error(t, err)
+}
+
+func TestIndicator_EvaluateCandles(t *testing.T) {
+	indicator := NewIndicator(NewExmo(Test()))
+
+	result, err := indicator.EvaluateCandles("stoch(2,1)", testCandles)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 80, 100.0 / 3}, result["k"], 1e-9)
+
+	_, err = indicator.EvaluateCandles("psar(0.3,0.2)", testCandles)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
@@ -39,6 +39,23 @@ func (c *Client) GetRequest(method string, url string, body io.Reader) ([]byte,
 	return bodyText, nil
 }
 
+type RequesterFunc func(method string, url string, body io.Reader) ([]byte, error)
+
+func (f RequesterFunc) GetRequest(method string, url string, body io.Reader) ([]byte, error) {
+	return f(method, url, body)
+}
+
+type Middleware func(Requester) Requester
+
+// Chain wraps r with the given middlewares. The first middleware is the outermost one,
+// so it sees the request first and the response last.
+func Chain(r Requester, mw ...Middleware) Requester {
+	for i := len(mw) - 1; i >= 0; i-- {
+		r = mw[i](r)
+	}
+	return r
+}
+
 type MockClient struct {
 }
 
@@ -68,14 +85,17 @@ func (m *MockClient) GetRequest(method string, url string, body io.Reader) ([]by
 	case "https://api.exmo.com/v1.1/currency":
 		return json.Marshal(Currencies{"ADA_BTC", "ADA_USD"})
 
+	case "https://api.exmo.com/v1.1/pair_settings":
+		return json.Marshal(PairSettings{"ADA_BTC": PairSetting{}, "ADA_USD": PairSetting{}})
+
 	case "https://api.exmo.com/v1.1/candles_history?symbol=ADA_BTC&resolution=30&from=1701367794&to=1701367795":
-		return json.Marshal(CandlesHistory{[]Candle{{C: 1}, {C: 2}, {C: 3}}})
+		return json.Marshal(CandlesHistory{Candles: []Candle{{C: 1}, {C: 2}, {C: 3}}})
 	case "https://api.exmo.com/v1.1/candles_history?symbol=ADA_BTC&resolution=30&from=1701289470&to=1701293070":
-		return json.Marshal(CandlesHistory{[]Candle{{C: 1}, {C: 2}, {C: 3}}})
+		return json.Marshal(CandlesHistory{Candles: []Candle{{C: 1}, {C: 2}, {C: 3}}})
 	case "https://api.exmo.com/v1.1/candles_history?symbol=ADA_BTC&resolution=30&from=1701289470&to=1701296670":
-		return json.Marshal(CandlesHistory{[]Candle{{C: 2}, {C: 3}, {C: 4}}})
+		return json.Marshal(CandlesHistory{Candles: []Candle{{C: 2}, {C: 3}, {C: 4}}})
 	case "https://api.exmo.com/v1.1/candles_history?symbol=ADA_BTC&resolution=30&from=1701289470&to=1701300270":
-		return json.Marshal(CandlesHistory{[]Candle{{C: 5}, {C: 6}, {C: 7}}})
+		return json.Marshal(CandlesHistory{Candles: []Candle{{C: 5}, {C: 6}, {C: 7}}})
 
 	default:
 	}
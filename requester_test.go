@@ -4,10 +4,8 @@ import (
 	"io"
 	"net/http"
 	"reflect"
-	"strconv"
 	"strings"
 	"testing"
-	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -21,14 +19,18 @@ func TestNewClient(t *testing.T) {
 
 func TestClient_GetRequest(t *testing.T) {
 	exmo := NewExmo()
+	recorder, err := NewRecorder(exmo.requester, "testdata/client_get_request.jsonl", ModeRecordMissing)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	type testData struct {
 		url         string
 		method      string
 		body        io.Reader
 		expectedErr bool
 	}
-	start := strconv.Itoa(int(time.Now().Add(-time.Hour * 24).Unix()))
-	end := strconv.Itoa(int(time.Now().Unix()))
+	start := "1701281394"
+	end := "1701367794"
 
 	testCases := []testData{
 		{url: "not url", method: "POST", body: io.Reader(nil), expectedErr: true},
@@ -39,7 +41,7 @@ func TestClient_GetRequest(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		result, err := exmo.requester.GetRequest(tc.method, tc.url, tc.body)
+		result, err := recorder.GetRequest(tc.method, tc.url, tc.body)
 		if tc.expectedErr {
 			if err == nil {
 				t.Errorf("url: %v: expected error, got nil", tc.url)
@@ -58,3 +60,46 @@ func TestClient_GetRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestRequesterFunc_GetRequest(t *testing.T) {
+	expected := []byte("data")
+	requester := RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		return append([]byte(method+" "+url+" "), expected...), nil
+	})
+
+	result, err := requester.GetRequest("GET", "url", nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if string(result) != "GET url data" {
+		t.Errorf("unexpected result: got %s, want %s", result, "GET url data")
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next Requester) Requester {
+			return RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+				calls = append(calls, name)
+				return next.GetRequest(method, url, body)
+			})
+		}
+	}
+
+	requester := Chain(&MockClient{}, middleware("first"), middleware("second"))
+	result, err := requester.GetRequest("POST", "https://api.exmo.com/v1.1/currency", nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if result == nil {
+		t.Errorf("unexpected nil result")
+	}
+	if !reflect.DeepEqual(calls, []string{"first", "second"}) {
+		t.Errorf("unexpected call order: got %v, want %v", calls, []string{"first", "second"})
+	}
+
+	if Chain(&MockClient{}) == nil {
+		t.Errorf("unexpected nil requester")
+	}
+}
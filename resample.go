@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+var ErrUnsupportedResolution = errors.New("unsupported resolution")
+
+// Resample aggregates candles into coarser candles of the given resolution. Buckets are aligned
+// to midnight in loc (UTC if nil), weekly buckets start on Monday. The resolution must divide
+// a day or be a Day or a Week.
+func Resample(candles []Candle, resolution time.Duration, loc *time.Location) ([]Candle, error) {
+	if resolution <= 0 || (Day%resolution != 0 && resolution != Week) {
+		return nil, fmt.Errorf("Resample -> %v: %w", resolution, ErrUnsupportedResolution)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	sorted := append([]Candle(nil), candles...)
+	sortCandles(sorted)
+
+	res := make([]Candle, 0)
+	for _, candle := range sorted {
+		start := bucketStart(time.UnixMilli(candle.T).In(loc), resolution).UnixMilli()
+		if len(res) == 0 || res[len(res)-1].T != start {
+			candle.T = start
+			res = append(res, candle)
+			continue
+		}
+
+		bucket := &res[len(res)-1]
+		if candle.H > bucket.H {
+			bucket.H = candle.H
+		}
+		if candle.L < bucket.L {
+			bucket.L = candle.L
+		}
+		bucket.C = candle.C
+		bucket.V += candle.V
+	}
+	return res, nil
+}
+
+func bucketStart(t time.Time, resolution time.Duration) time.Time {
+	year, month, day := t.Date()
+	switch resolution {
+	case Week:
+		return time.Date(year, month, day-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case Day:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	elapsed := t.Sub(midnight)
+	return midnight.Add(elapsed - elapsed%resolution)
+}
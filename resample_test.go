@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResample(t *testing.T) {
+	minute := int64(60000)
+	candles := []Candle{
+		{T: 15 * minute, O: 3, H: 6, L: 3, C: 5, V: 1},
+		{T: 0, O: 1, H: 2, L: 0.5, C: 2, V: 1},
+		{T: 5 * minute, O: 2, H: 4, L: 1, C: 3, V: 2},
+		{T: 10 * minute, O: 3, H: 3.5, L: 2, C: 2.5, V: 3},
+	}
+
+	result, err := Resample(candles, 15*time.Minute, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []Candle{
+		{T: 0, O: 1, H: 4, L: 0.5, C: 2.5, V: 6},
+		{T: 15 * minute, O: 3, H: 6, L: 3, C: 5, V: 1},
+	}, result)
+
+	_, err = Resample(candles, 7*time.Minute, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedResolution)
+}
+
+func TestResample_calendarAligned(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	sunday := time.Date(2023, 12, 3, 22, 0, 0, 0, time.UTC)
+	candles := []Candle{
+		{T: sunday.UnixMilli(), O: 1, H: 1, L: 1, C: 1, V: 1},
+		{T: sunday.Add(time.Hour).UnixMilli(), O: 2, H: 2, L: 2, C: 2, V: 1},
+		{T: sunday.Add(3 * time.Hour).UnixMilli(), O: 3, H: 3, L: 3, C: 3, V: 1},
+	}
+
+	days, err := Resample(candles, Day, moscow)
+	assert.NoError(t, err)
+	assert.Len(t, days, 1)
+	assert.Equal(t, time.Date(2023, 12, 4, 0, 0, 0, 0, moscow).UnixMilli(), days[0].T)
+
+	utcDays, err := Resample(candles, Day, time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, utcDays, 2)
+
+	weeks, err := Resample(candles, Week, moscow)
+	assert.NoError(t, err)
+	assert.Equal(t, []Candle{{T: time.Date(2023, 12, 4, 0, 0, 0, 0, moscow).UnixMilli(), O: 1, H: 3, L: 1, C: 3, V: 3}}, weeks)
+
+	fourHours, err := Resample(candles, 4*time.Hour, moscow)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 12, 4, 0, 0, 0, 0, moscow).UnixMilli(), fourHours[0].T)
+	assert.Equal(t, time.Date(2023, 12, 4, 4, 0, 0, 0, moscow).UnixMilli(), fourHours[1].T)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Point is a value of an indicator with the bar it belongs to. Valid is false for the values
+// calculated from not enough data, which are NaN.
+type Point struct {
+	Start time.Time
+	End   time.Time
+	Value float64
+	Valid bool
+}
+
+type Series []Point
+
+// Row is a point in time of joined series. Values and Valid hold NaN and false for the series
+// that have no point at Start.
+type Row struct {
+	Start  time.Time
+	End    time.Time
+	Values []float64
+	Valid  []bool
+}
+
+// NewSeries pairs values with the candles they were calculated from. Values beyond the candles,
+// like the displaced Senkou lines of Ichimoku, continue the resolution grid.
+func NewSeries(candles []Candle, resolution time.Duration, values []float64) Series {
+	res := make(Series, 0, len(values))
+	for i, value := range values {
+		var start time.Time
+		switch {
+		case i < len(candles):
+			start = time.UnixMilli(candles[i].T)
+		case len(candles) > 0:
+			start = time.UnixMilli(candles[len(candles)-1].T).Add(time.Duration(i-len(candles)+1) * resolution)
+		default:
+			continue
+		}
+		res = append(res, newPoint(start, start.Add(resolution), value))
+	}
+	return res
+}
+
+func newPoint(start, end time.Time, value float64) Point {
+	return Point{Start: start, End: end, Value: value, Valid: !math.IsNaN(value) && !math.IsInf(value, 0)}
+}
+
+func (s Series) Values() []float64 {
+	res := make([]float64, 0, len(s))
+	for _, point := range s {
+		res = append(res, point.Value)
+	}
+	return res
+}
+
+// Slice returns the points that start in [from, to).
+func (s Series) Slice(from, to time.Time) Series {
+	res := make(Series, 0)
+	for _, point := range s {
+		if !point.Start.Before(from) && point.Start.Before(to) {
+			res = append(res, point)
+		}
+	}
+	return res
+}
+
+// At returns the point whose bar contains t.
+func (s Series) At(t time.Time) (Point, bool) {
+	for _, point := range s {
+		if !t.Before(point.Start) && t.Before(point.End) {
+			return point, true
+		}
+	}
+	return Point{}, false
+}
+
+// Valid returns the points with valid values only.
+func (s Series) Valid() Series {
+	res := make(Series, 0, len(s))
+	for _, point := range s {
+		if point.Valid {
+			res = append(res, point)
+		}
+	}
+	return res
+}
+
+// Align returns the points of a and b that start at the same time, in the order of a.
+func Align(a, b Series) (Series, Series) {
+	byStart := make(map[int64]Point, len(b))
+	for _, point := range b {
+		byStart[point.Start.UnixNano()] = point
+	}
+
+	alignedA, alignedB := make(Series, 0), make(Series, 0)
+	for _, point := range a {
+		if other, ok := byStart[point.Start.UnixNano()]; ok {
+			alignedA, alignedB = append(alignedA, point), append(alignedB, other)
+		}
+	}
+	return alignedA, alignedB
+}
+
+// Join merges series into rows by the start of their points, sorted by time.
+func Join(series ...Series) []Row {
+	rows := make(map[int64]*Row)
+	for i, s := range series {
+		for _, point := range s {
+			key := point.Start.UnixNano()
+			row, ok := rows[key]
+			if !ok {
+				row = &Row{Start: point.Start, End: point.End, Values: nanSeries(len(series)), Valid: make([]bool, len(series))}
+				rows[key] = row
+			}
+			row.Values[i], row.Valid[i] = point.Value, point.Valid
+		}
+	}
+
+	res := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, *row)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Start.Before(res[j].Start) })
+	return res
+}
+
+// SeriesOutput holds the lines of an indicator paired with their bars, by the names of Output.
+type SeriesOutput map[string]Series
+
+// NewSeriesOutput pairs every line of output with the candles it was calculated from.
+func NewSeriesOutput(candles []Candle, resolution time.Duration, output Output) SeriesOutput {
+	res := make(SeriesOutput, len(output))
+	for name, values := range output {
+		res[name] = NewSeries(candles, resolution, values)
+	}
+	return res
+}
+
+func (o SeriesOutput) Values() Output {
+	res := make(Output, len(o))
+	for name, series := range o {
+		res[name] = series.Values()
+	}
+	return res
+}
+
+// candleSeries calculates the lines of an indicator over the candles of pair and pairs them with the candles.
+func (i *Indicator) candleSeries(pair string, limit int, from, to time.Time, calculate func(candles []Candle) Output) (SeriesOutput, error) {
+	candles, err := i.GetCandles(pair, limit, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return NewSeriesOutput(candles, time.Duration(limit)*time.Minute, calculate(candles)), nil
+}
+
+// EvaluateSeries is Evaluate with every value paired with its candle.
+func (i *Indicator) EvaluateSeries(spec string, pair string, limit int, from, to time.Time) (SeriesOutput, error) {
+	call, err := i.registry.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_EvaluateSeries -> %w", err)
+	}
+
+	var calculateErr error
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		var output Output
+		output, calculateErr = call.Calculate(candles, i.source)
+		return output
+	})
+	if err == nil {
+		err = calculateErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_EvaluateSeries -> %w", err)
+	}
+	return res, nil
+}
+
+// SMASeries is SMA with every value paired with its part of the time between from and to. As in
+// SMA, the value of a part is calculated from the prices from from to its End.
+func (i *Indicator) SMASeries(pair string, limit, period int, from, to time.Time) (Series, error) {
+	res, err := i.periodSeries(periodsSMA, pair, limit, period, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_SMASeries -> %w", err)
+	}
+	return res, nil
+}
+
+// EMASeries is EMA with every value paired with its part of the time between from and to, like SMASeries.
+func (i *Indicator) EMASeries(pair string, limit, period int, from, to time.Time) (Series, error) {
+	res, err := i.periodSeries(periodsEMA, pair, limit, period, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_EMASeries -> %w", err)
+	}
+	return res, nil
+}
+
+func (i *Indicator) periodSeries(name string, pair string, limit, period int, from, to time.Time) (Series, error) {
+	data, err := i.GetDataPerPeriods(pair, limit, period, from, to)
+	if err != nil {
+		return nil, err
+	}
+	values, err := i.calculatePeriods(name, data, period)
+	if err != nil {
+		return nil, err
+	}
+
+	ends := periodEnds(period, from, to)
+	res := make(Series, 0, len(values))
+	start := from
+	for j, value := range values {
+		if j >= len(ends) {
+			break
+		}
+		res = append(res, newPoint(start, ends[j], value))
+		start = ends[j]
+	}
+	return res, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSeries(startMinute int, values ...float64) Series {
+	res := make(Series, 0, len(values))
+	for i, value := range values {
+		start := time.Unix(int64(startMinute+i)*60, 0)
+		res = append(res, newPoint(start, start.Add(time.Minute), value))
+	}
+	return res
+}
+
+func TestNewSeries(t *testing.T) {
+	result := NewSeries(testCandles, time.Minute, []float64{math.NaN(), 1, 2, 3})
+
+	assert.Len(t, result, 4)
+	assert.False(t, result[0].Valid)
+	assert.True(t, result[1].Valid)
+	assert.Equal(t, time.UnixMilli(60000), result[1].Start)
+	assert.Equal(t, time.UnixMilli(120000), result[1].End)
+	assert.Equal(t, time.UnixMilli(180000), result[3].Start)
+	assert.Empty(t, NewSeries(nil, time.Minute, []float64{1}))
+}
+
+func TestSeries_Slice(t *testing.T) {
+	series := testSeries(0, 1, 2, 3, 4)
+
+	assert.Equal(t, []float64{2, 3}, series.Slice(time.Unix(60, 0), time.Unix(180, 0)).Values())
+	assert.Empty(t, series.Slice(time.Unix(600, 0), time.Unix(900, 0)))
+}
+
+func TestSeries_At(t *testing.T) {
+	series := testSeries(0, 1, 2)
+
+	result, ok := series.At(time.Unix(90, 0))
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, result.Value)
+
+	_, ok = series.At(time.Unix(120, 0))
+	assert.False(t, ok)
+}
+
+func TestSeries_Valid(t *testing.T) {
+	assert.Equal(t, []float64{2}, testSeries(0, math.NaN(), 2).Valid().Values())
+}
+
+func TestAlign(t *testing.T) {
+	a, b := Align(testSeries(0, 1, 2, 3), testSeries(1, 20, 30, 40))
+
+	assert.Equal(t, []float64{2, 3}, a.Values())
+	assert.Equal(t, []float64{20, 30}, b.Values())
+}
+
+func TestJoin(t *testing.T) {
+	result := Join(testSeries(0, 1, 2), testSeries(1, 20, math.NaN()))
+
+	assert.Len(t, result, 3)
+	assert.Equal(t, time.Unix(0, 0), result[0].Start)
+	assert.Equal(t, 1.0, result[0].Values[0])
+	assert.True(t, math.IsNaN(result[0].Values[1]))
+	assert.Equal(t, []bool{true, false}, result[0].Valid)
+	assert.Equal(t, []float64{2, 20}, result[1].Values)
+	assert.Equal(t, []bool{false, false}, result[2].Valid)
+}
+
+func TestNewSeriesOutput(t *testing.T) {
+	result := NewSeriesOutput(testCandles, time.Minute, Output{"k": {1, 2, 3}, "d": {math.NaN(), 1, 2}})
+
+	assert.Len(t, result, 2)
+	assert.Equal(t, []float64{1, 2, 3}, result["k"].Values())
+	assert.False(t, result["d"][0].Valid)
+	assert.Equal(t, time.UnixMilli(120000), result["d"][2].Start)
+}
+
+func TestIndicator_EvaluateSeries(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"t":0,"c":1},{"t":1800000,"c":2},{"t":3600000,"c":1},{"t":5400000,"c":2}]`))
+	from, to := time.Unix(0, 0), time.Unix(7200, 0)
+
+	result, err := indicator.EvaluateSeries("rsi(2)", "ADA_BTC", 30, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 50, 75}, result[ValueLine].Values(), 1e-9)
+	assert.Equal(t, time.Unix(5400, 0), result[ValueLine][3].Start)
+
+	_, err = indicator.EvaluateSeries("rsi(x)", "ADA_BTC", 30, from, to)
+	assert.ErrorIs(t, err, ErrInvalidSpec)
+
+	_, err = indicator.EvaluateSeries("psar(0.3,0.2)", "ADA_BTC", 30, from, to)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestIndicator_SMASeries(t *testing.T) {
+	indicator := NewIndicator(NewExmo(Test()))
+	from, to := time.Unix(1701289470, 0), time.Unix(1701300270, 0)
+
+	result, err := indicator.SMASeries("ADA_BTC", 30, 3, from, to)
+	assert.NoError(t, err)
+	sma, err := indicator.SMA("ADA_BTC", 30, 3, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, sma, result.Values())
+	assert.Equal(t, from, result[0].Start)
+	assert.Equal(t, time.Unix(1701293070, 0), result[0].End)
+	assert.Equal(t, result[0].End, result[1].Start)
+	assert.Equal(t, result[1].End, result[2].Start)
+	assert.Equal(t, to, result[2].End)
+
+	_, err = indicator.SMASeries("BTC_USD", 30, 3, from, to)
+	assert.Error(t, err)
+}
+
+func TestIndicator_EMASeries(t *testing.T) {
+	indicator := NewIndicator(NewExmo(Test()))
+	from, to := time.Unix(1701289470, 0), time.Unix(1701300270, 0)
+
+	result, err := indicator.EMASeries("ADA_BTC", 30, 3, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 2.5, 4.5}, result.Values())
+	assert.True(t, result[2].Valid)
+	assert.Equal(t, to, result[2].End)
+}
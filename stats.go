@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Year is the length of a year of the market that trades around the clock.
+const Year = 365 * Day
+
+type ReturnKind int
+
+const (
+	SimpleReturns ReturnKind = iota
+	LogReturns
+)
+
+// Returns calculates the returns of prices. The first return is NaN, as there is no price before it.
+func Returns(prices []float64, kind ReturnKind) []float64 {
+	res := nanSeries(len(prices))
+	for i := 1; i < len(prices); i++ {
+		if kind == LogReturns {
+			res[i] = math.Log(prices[i] / prices[i-1])
+		} else {
+			res[i] = prices[i]/prices[i-1] - 1
+		}
+	}
+	return res
+}
+
+// RollingVolatility is the sample standard deviation of the period returns up to every return.
+// It is NaN until there are period valid returns.
+func RollingVolatility(returns []float64, period int) []float64 {
+	res := nanSeries(len(returns))
+	if period < 2 {
+		return res
+	}
+	for i := period - 1; i < len(returns); i++ {
+		res[i] = stdDev(returns[i-period+1 : i+1])
+	}
+	return res
+}
+
+// Annualize scales the volatility of returns of the given resolution to a year.
+func Annualize(volatility float64, resolution time.Duration) float64 {
+	return volatility * math.Sqrt(float64(Year)/float64(resolution))
+}
+
+// AnnualizedVolatility is the annualized sample standard deviation of all the valid returns.
+func AnnualizedVolatility(returns []float64, resolution time.Duration) (float64, error) {
+	if resolution <= 0 {
+		return 0, fmt.Errorf("AnnualizedVolatility -> %v: %w", resolution, ErrUnsupportedResolution)
+	}
+	return Annualize(stdDev(validValues(returns)), resolution), nil
+}
+
+func validValues(data []float64) []float64 {
+	res := make([]float64, 0, len(data))
+	for _, value := range data {
+		if !math.IsNaN(value) && !math.IsInf(value, 0) {
+			res = append(res, value)
+		}
+	}
+	return res
+}
+
+func mean(data []float64) float64 {
+	var sum float64
+	for _, value := range data {
+		sum += value
+	}
+	return sum / float64(len(data))
+}
+
+// stdDev is the sample standard deviation of data, NaN for less than two values.
+func stdDev(data []float64) float64 {
+	return math.Sqrt(covariance(data, data))
+}
+
+// covariance is the sample covariance of a and b of the same length, NaN for less than two values.
+func covariance(a, b []float64) float64 {
+	if len(a) < 2 {
+		return math.NaN()
+	}
+	meanA, meanB := mean(a), mean(b)
+	var sum float64
+	for i := range a {
+		sum += (a[i] - meanA) * (b[i] - meanB)
+	}
+	return sum / float64(len(a)-1)
+}
+
+// validPairs returns the values of a and b at the indices where both are valid.
+func validPairs(a, b []float64) ([]float64, []float64) {
+	validA, validB := make([]float64, 0, len(a)), make([]float64, 0, len(b))
+	for i := range a {
+		if i < len(b) && !math.IsNaN(a[i]) && !math.IsNaN(b[i]) {
+			validA, validB = append(validA, a[i]), append(validB, b[i])
+		}
+	}
+	return validA, validB
+}
+
+// Correlation is the Pearson correlation of a and b at the indices where both are valid.
+func Correlation(a, b []float64) float64 {
+	a, b = validPairs(a, b)
+	return covariance(a, b) / math.Sqrt(covariance(a, a)*covariance(b, b))
+}
+
+// Beta is the covariance of returns with the reference returns divided by the variance of
+// the reference returns, at the indices where both are valid.
+func Beta(returns, reference []float64) float64 {
+	returns, reference = validPairs(returns, reference)
+	return covariance(returns, reference) / covariance(reference, reference)
+}
+
+// RollingBeta is Beta over the period returns up to every return.
+func RollingBeta(returns, reference []float64, period int) []float64 {
+	res := nanSeries(len(returns))
+	for i := period - 1; i >= 0 && i < len(returns) && i < len(reference); i++ {
+		res[i] = Beta(returns[i-period+1:i+1], reference[i-period+1:i+1])
+	}
+	return res
+}
+
+// CorrelationMatrix holds the correlations of the returns of Pairs over the period ending at End.
+type CorrelationMatrix struct {
+	Start  time.Time
+	End    time.Time
+	Pairs  []string
+	Values [][]float64
+}
+
+// RollingCorrelationMatrix joins returns by time and correlates every two of them over the period
+// rows up to every row. The correlation of returns with less than two common valid values is NaN.
+func RollingCorrelationMatrix(pairs []string, returns []Series, period int) ([]CorrelationMatrix, error) {
+	if period < 2 {
+		return nil, fmt.Errorf("RollingCorrelationMatrix -> %w", ErrInvalidPeriod)
+	}
+	if len(pairs) != len(returns) {
+		return nil, fmt.Errorf("RollingCorrelationMatrix -> %d pairs for %d series: %w", len(pairs), len(returns), ErrInvalidParams)
+	}
+
+	rows := Join(returns...)
+	columns := make([][]float64, len(returns))
+	for j := range columns {
+		columns[j] = make([]float64, 0, len(rows))
+		for _, row := range rows {
+			columns[j] = append(columns[j], row.Values[j])
+		}
+	}
+
+	res := make([]CorrelationMatrix, 0, len(rows))
+	for r := period - 1; r < len(rows); r++ {
+		values := make([][]float64, len(returns))
+		for j := range values {
+			values[j] = make([]float64, len(returns))
+			values[j][j] = 1
+			for k := 0; k < j; k++ {
+				correlation := Correlation(columns[j][r-period+1:r+1], columns[k][r-period+1:r+1])
+				values[j][k], values[k][j] = correlation, correlation
+			}
+		}
+		res = append(res, CorrelationMatrix{Start: rows[r-period+1].Start, End: rows[r].End, Pairs: pairs, Values: values})
+	}
+	return res, nil
+}
+
+// Drawdown is the fall of every price below the highest price before it, as a fraction of that price.
+func Drawdown(prices []float64) []float64 {
+	res := make([]float64, 0, len(prices))
+	peak := math.Inf(-1)
+	for _, price := range prices {
+		peak = math.Max(peak, price)
+		res = append(res, 1-price/peak)
+	}
+	return res
+}
+
+// DrawdownStats describes the drawdowns of the closes of candles. Max is the deepest drawdown,
+// from the close at Peak to the close at Trough, recovered at Recovery or not yet if Recovery
+// is zero. Longest is the longest time the closes stayed below a previous peak.
+type DrawdownStats struct {
+	Max      float64
+	Peak     time.Time
+	Trough   time.Time
+	Recovery time.Time
+	Current  float64
+	Longest  time.Duration
+}
+
+func DrawdownStatistics(candles []Candle) DrawdownStats {
+	var stats DrawdownStats
+	if len(candles) == 0 {
+		return stats
+	}
+
+	drawdowns := Drawdown(Prices(candles, SourceClose))
+	trough := 0
+	for i, drawdown := range drawdowns {
+		if drawdown > drawdowns[trough] {
+			trough = i
+		}
+	}
+	stats.Max, stats.Current = drawdowns[trough], drawdowns[len(drawdowns)-1]
+
+	if stats.Max > 0 {
+		peak := trough
+		for drawdowns[peak] > 0 {
+			peak--
+		}
+		stats.Peak, stats.Trough = time.UnixMilli(candles[peak].T), time.UnixMilli(candles[trough].T)
+		for i := trough + 1; i < len(drawdowns); i++ {
+			if drawdowns[i] == 0 {
+				stats.Recovery = time.UnixMilli(candles[i].T)
+				break
+			}
+		}
+	}
+
+	peak := 0
+	for i, drawdown := range drawdowns {
+		if drawdown == 0 {
+			peak = i
+			continue
+		}
+		if i+1 < len(drawdowns) && drawdowns[i+1] > 0 {
+			continue
+		}
+		end := minInt(i+1, len(drawdowns)-1)
+		stats.Longest = maxDuration(stats.Longest, time.Duration(candles[end].T-candles[peak].T)*time.Millisecond)
+	}
+	return stats
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Returns calculates the returns of the closes of pair between from and to.
+func (i *Indicator) Returns(pair string, limit int, kind ReturnKind, from, to time.Time) (Series, error) {
+	candles, err := i.GetCandles(pair, limit, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_Returns -> %w", err)
+	}
+	return NewSeries(candles, time.Duration(limit)*time.Minute, Returns(Prices(candles, SourceClose), kind)), nil
+}
+
+// Volatility is the annualized rolling volatility of the log returns of pair between from and to.
+func (i *Indicator) Volatility(pair string, limit, period int, from, to time.Time) (Series, error) {
+	if period < 2 {
+		return nil, fmt.Errorf("Indicator_Volatility -> %w", ErrInvalidPeriod)
+	}
+	returns, err := i.Returns(pair, limit, LogReturns, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_Volatility -> %w", err)
+	}
+
+	resolution := time.Duration(limit) * time.Minute
+	volatility := RollingVolatility(returns.Values(), period)
+	res := make(Series, 0, len(returns))
+	for j, point := range returns {
+		res = append(res, newPoint(point.Start, point.End, Annualize(volatility[j], resolution)))
+	}
+	return res, nil
+}
+
+// CorrelationMatrix correlates the log returns of pairs over the period candles up to every candle
+// between from and to.
+func (i *Indicator) CorrelationMatrix(pairs []string, limit, period int, from, to time.Time) ([]CorrelationMatrix, error) {
+	returns := make([]Series, 0, len(pairs))
+	for _, pair := range pairs {
+		series, err := i.Returns(pair, limit, LogReturns, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("Indicator_CorrelationMatrix -> %w", err)
+		}
+		returns = append(returns, series)
+	}
+
+	res, err := RollingCorrelationMatrix(pairs, returns, period)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_CorrelationMatrix -> %w", err)
+	}
+	return res, nil
+}
+
+// Beta calculates the beta of the log returns of pair against the ones of reference, like BTC_USD,
+// over the candles between from and to that both pairs have.
+func (i *Indicator) Beta(pair, reference string, limit int, from, to time.Time) (float64, error) {
+	returns, err := i.Returns(pair, limit, LogReturns, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("Indicator_Beta -> %w", err)
+	}
+	referenceReturns, err := i.Returns(reference, limit, LogReturns, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("Indicator_Beta -> %w", err)
+	}
+
+	returns, referenceReturns = Align(returns, referenceReturns)
+	return Beta(returns.Values(), referenceReturns.Values()), nil
+}
+
+// Drawdowns describes the drawdowns of the closes of pair between from and to.
+func (i *Indicator) Drawdowns(pair string, limit int, from, to time.Time) (DrawdownStats, error) {
+	candles, err := i.GetCandles(pair, limit, from, to)
+	if err != nil {
+		return DrawdownStats{}, fmt.Errorf("Indicator_Drawdowns -> %w", err)
+	}
+	return DrawdownStatistics(candles), nil
+}
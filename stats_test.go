@@ -0,0 +1,118 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturns(t *testing.T) {
+	nan := math.NaN()
+	prices := []float64{100, 110, 99}
+
+	assertSeries(t, []float64{nan, 0.1, -0.1}, Returns(prices, SimpleReturns), 1e-9)
+	assertSeries(t, []float64{nan, math.Log(1.1), math.Log(0.9)}, Returns(prices, LogReturns), 1e-9)
+}
+
+func TestRollingVolatility(t *testing.T) {
+	nan := math.NaN()
+
+	assertSeries(t, []float64{nan, nan, math.Sqrt(0.5), math.Sqrt(0.5)}, RollingVolatility([]float64{nan, 1, 2, 3}, 2), 1e-9)
+	assertSeries(t, []float64{nan, nan}, RollingVolatility([]float64{1, 2}, 1), 0)
+}
+
+func TestAnnualizedVolatility(t *testing.T) {
+	assert.InDelta(t, math.Sqrt(365), Annualize(1, Day), 1e-9)
+
+	volatility, err := AnnualizedVolatility([]float64{math.NaN(), 1, 2, 3}, Day)
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Sqrt(365), volatility, 1e-9)
+
+	_, err = AnnualizedVolatility([]float64{1, 2}, 0)
+	assert.ErrorIs(t, err, ErrUnsupportedResolution)
+}
+
+func TestCorrelation(t *testing.T) {
+	assert.InDelta(t, -1, Correlation([]float64{math.NaN(), 1, 2, 3}, []float64{5, 3, 2, 1}), 1e-9)
+	assert.InDelta(t, 1, Correlation([]float64{1, 2, 4}, []float64{2, 4, 8}), 1e-9)
+	assert.True(t, math.IsNaN(Correlation([]float64{1}, []float64{2})))
+}
+
+func TestBeta(t *testing.T) {
+	nan := math.NaN()
+
+	assert.InDelta(t, 2, Beta([]float64{2, 4, 6}, []float64{1, 2, 3}), 1e-9)
+	assertSeries(t, []float64{nan, 2, 2}, RollingBeta([]float64{2, 4, 6}, []float64{1, 2, 3}, 2), 1e-9)
+}
+
+func TestRollingCorrelationMatrix(t *testing.T) {
+	nan := math.NaN()
+	candles := closeCandles(1, 2, 3, 4)
+	returns := []Series{
+		NewSeries(candles, time.Minute, []float64{nan, 1, 2, 3}),
+		NewSeries(candles, time.Minute, []float64{nan, 2, 4, 6}),
+		NewSeries(candles[1:], time.Minute, []float64{3, 2, 1}),
+	}
+
+	result, err := RollingCorrelationMatrix([]string{"A", "B", "C"}, returns, 3)
+
+	assert.NoError(t, err)
+	if assert.Len(t, result, 2) {
+		assert.Equal(t, time.UnixMilli(0), result[0].Start)
+		assert.Equal(t, time.UnixMilli(180000), result[0].End)
+		assert.Equal(t, []string{"A", "B", "C"}, result[0].Pairs)
+		expected := [][]float64{{1, 1, -1}, {1, 1, -1}, {-1, -1, 1}}
+		for j := range expected {
+			assertSeries(t, expected[j], result[1].Values[j], 1e-9)
+		}
+	}
+
+	_, err = RollingCorrelationMatrix([]string{"A"}, returns, 3)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+	_, err = RollingCorrelationMatrix([]string{"A", "B", "C"}, returns, 1)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestDrawdownStatistics(t *testing.T) {
+	assertSeries(t, []float64{0, 0, 0.25, 0}, Drawdown([]float64{100, 120, 90, 130}), 1e-9)
+
+	stats := DrawdownStatistics(closeCandles(100, 120, 90, 108, 130, 117))
+
+	assert.InDelta(t, 0.25, stats.Max, 1e-9)
+	assert.Equal(t, time.UnixMilli(60000), stats.Peak)
+	assert.Equal(t, time.UnixMilli(120000), stats.Trough)
+	assert.Equal(t, time.UnixMilli(240000), stats.Recovery)
+	assert.InDelta(t, 0.1, stats.Current, 1e-9)
+	assert.Equal(t, 3*time.Minute, stats.Longest)
+
+	stats = DrawdownStatistics(closeCandles(100, 90, 80))
+	assert.True(t, stats.Recovery.IsZero())
+	assert.Equal(t, 2*time.Minute, stats.Longest)
+
+	assert.Equal(t, DrawdownStats{}, DrawdownStatistics(nil))
+}
+
+func TestIndicator_Beta(t *testing.T) {
+	exchange := candlesExchange(`[{"t":0,"o":1,"c":1,"h":2,"l":0},{"t":60000,"o":1,"c":3,"h":4,"l":1},{"t":120000,"o":3,"c":2,"h":3,"l":2}]`)
+	indicator := NewIndicator(exchange)
+	from, to := time.Unix(0, 0), time.Unix(180, 0)
+
+	beta, err := indicator.Beta("ETH_USD", "BTC_USD", 1, from, to)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, beta, 1e-9)
+
+	matrices, err := indicator.CorrelationMatrix([]string{"ETH_USD", "BTC_USD"}, 1, 3, from, to)
+	assert.NoError(t, err)
+	if assert.Len(t, matrices, 1) {
+		assert.InDelta(t, 1, matrices[0].Values[0][1], 1e-9)
+	}
+
+	volatility, err := indicator.Volatility("BTC_USD", 1, 2, from, to)
+	assert.NoError(t, err)
+	if assert.Len(t, volatility, 3) {
+		assert.False(t, volatility[1].Valid)
+		assert.InDelta(t, Annualize(stdDev([]float64{math.Log(3), math.Log(2.0 / 3)}), time.Minute), volatility[2].Value, 1e-9)
+	}
+}
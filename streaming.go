@@ -0,0 +1,409 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// StreamingIndicator processes one closed candle at a time in O(1). Value is NaN until
+// the indicator has seen enough candles, the same as the warm-up of the batch indicators.
+type StreamingIndicator interface {
+	Update(candle Candle)
+	Value() float64
+	Ready() bool
+}
+
+// window is a fixed size ring buffer of the latest values.
+type window struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func newWindow(size int) *window {
+	return &window{values: make([]float64, size)}
+}
+
+// push adds value and returns the value it replaced, if the window was full.
+func (w *window) push(value float64) (float64, bool) {
+	old, full := w.values[w.next], w.full
+	w.values[w.next] = value
+	w.next = (w.next + 1) % len(w.values)
+	if w.next == 0 {
+		w.full = true
+	}
+	return old, full
+}
+
+type StreamingSMA struct {
+	source PriceSource
+	window *window
+	sum    float64
+}
+
+func NewStreamingSMA(period int, source PriceSource) (*StreamingSMA, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("NewStreamingSMA -> %w", ErrInvalidPeriod)
+	}
+	return &StreamingSMA{source: source, window: newWindow(period)}, nil
+}
+
+func (s *StreamingSMA) Update(candle Candle) {
+	price := s.source.Price(candle)
+	s.sum += price
+	if old, full := s.window.push(price); full {
+		s.sum -= old
+	}
+}
+
+func (s *StreamingSMA) Value() float64 {
+	if !s.Ready() {
+		return math.NaN()
+	}
+	return s.sum / float64(len(s.window.values))
+}
+
+func (s *StreamingSMA) Ready() bool {
+	return s.window.full
+}
+
+// StreamingWMA keeps the weighted sum and the plain sum of the window, so that shifting
+// the window is O(1).
+type StreamingWMA struct {
+	source   PriceSource
+	window   *window
+	sum      float64
+	weighted float64
+	count    int
+}
+
+func NewStreamingWMA(period int, source PriceSource) (*StreamingWMA, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("NewStreamingWMA -> %w", ErrInvalidPeriod)
+	}
+	return &StreamingWMA{source: source, window: newWindow(period)}, nil
+}
+
+func (s *StreamingWMA) Update(candle Candle) {
+	price := s.source.Price(candle)
+	old, full := s.window.push(price)
+	if !full {
+		s.count++
+		s.weighted += float64(s.count) * price
+		s.sum += price
+		return
+	}
+	s.weighted += float64(s.count)*price - s.sum
+	s.sum += price - old
+}
+
+func (s *StreamingWMA) Value() float64 {
+	if !s.Ready() {
+		return math.NaN()
+	}
+	period := float64(len(s.window.values))
+	return s.weighted / (period * (period + 1) / 2)
+}
+
+func (s *StreamingWMA) Ready() bool {
+	return s.window.full
+}
+
+// smoother is the streaming counterpart of smoothedAverage: it is seeded with the simple average
+// of the first period values.
+type smoother struct {
+	period int
+	alpha  float64
+	count  int
+	value  float64
+}
+
+func (s *smoother) update(value float64) {
+	if s.count < s.period {
+		s.count++
+		s.value += (value - s.value) / float64(s.count)
+		return
+	}
+	s.value = value*s.alpha + s.value*(1-s.alpha)
+}
+
+func (s *smoother) ready() bool {
+	return s.count >= s.period
+}
+
+func (s *smoother) result() float64 {
+	if !s.ready() {
+		return math.NaN()
+	}
+	return s.value
+}
+
+type StreamingEMA struct {
+	source   PriceSource
+	smoother smoother
+}
+
+func NewStreamingEMA(period int, source PriceSource) (*StreamingEMA, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("NewStreamingEMA -> %w", ErrInvalidPeriod)
+	}
+	return &StreamingEMA{source: source, smoother: smoother{period: period, alpha: 2 / float64(period+1)}}, nil
+}
+
+func (s *StreamingEMA) Update(candle Candle) {
+	s.smoother.update(s.source.Price(candle))
+}
+
+func (s *StreamingEMA) Value() float64 {
+	return s.smoother.result()
+}
+
+func (s *StreamingEMA) Ready() bool {
+	return s.smoother.ready()
+}
+
+type StreamingRSI struct {
+	source PriceSource
+	prev   float64
+	seen   bool
+	gains  smoother
+	losses smoother
+}
+
+func NewStreamingRSI(period int, source PriceSource) (*StreamingRSI, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("NewStreamingRSI -> %w", ErrInvalidPeriod)
+	}
+	alpha := 1 / float64(period)
+	return &StreamingRSI{
+		source: source,
+		gains:  smoother{period: period, alpha: alpha},
+		losses: smoother{period: period, alpha: alpha},
+	}, nil
+}
+
+func (s *StreamingRSI) Update(candle Candle) {
+	price := s.source.Price(candle)
+	if s.seen {
+		change := price - s.prev
+		s.gains.update(math.Max(change, 0))
+		s.losses.update(math.Max(-change, 0))
+	}
+	s.prev, s.seen = price, true
+}
+
+func (s *StreamingRSI) Value() float64 {
+	if !s.Ready() {
+		return math.NaN()
+	}
+	if s.losses.value == 0 {
+		return 100
+	}
+	return 100 - 100/(1+s.gains.value/s.losses.value)
+}
+
+func (s *StreamingRSI) Ready() bool {
+	return s.gains.ready()
+}
+
+type StreamingATR struct {
+	prevClose float64
+	seen      bool
+	ranges    smoother
+}
+
+func NewStreamingATR(period int) (*StreamingATR, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("NewStreamingATR -> %w", ErrInvalidPeriod)
+	}
+	return &StreamingATR{ranges: smoother{period: period, alpha: 1 / float64(period)}}, nil
+}
+
+func (s *StreamingATR) Update(candle Candle) {
+	trueRange := candle.H - candle.L
+	if s.seen {
+		trueRange = math.Max(trueRange, math.Max(math.Abs(candle.H-s.prevClose), math.Abs(candle.L-s.prevClose)))
+	}
+	s.ranges.update(trueRange)
+	s.prevClose, s.seen = candle.C, true
+}
+
+func (s *StreamingATR) Value() float64 {
+	return s.ranges.result()
+}
+
+func (s *StreamingATR) Ready() bool {
+	return s.ranges.ready()
+}
+
+// StreamingMACD reports the MACD line as its Value, the signal line and the histogram
+// are available separately.
+type StreamingMACD struct {
+	fast   *StreamingEMA
+	slow   *StreamingEMA
+	signal smoother
+}
+
+func NewStreamingMACD(fast, slow, signal int, source PriceSource) (*StreamingMACD, error) {
+	if fast <= 0 || slow <= 0 || signal <= 0 {
+		return nil, fmt.Errorf("NewStreamingMACD -> %w", ErrInvalidPeriod)
+	}
+	if fast >= slow {
+		return nil, fmt.Errorf("NewStreamingMACD -> fast period must be less than slow: %w", ErrInvalidParams)
+	}
+
+	fastEMA, _ := NewStreamingEMA(fast, source)
+	slowEMA, _ := NewStreamingEMA(slow, source)
+	return &StreamingMACD{fast: fastEMA, slow: slowEMA, signal: smoother{period: signal, alpha: 2 / float64(signal+1)}}, nil
+}
+
+func (s *StreamingMACD) Update(candle Candle) {
+	s.fast.Update(candle)
+	s.slow.Update(candle)
+	if s.slow.Ready() {
+		s.signal.update(s.Value())
+	}
+}
+
+func (s *StreamingMACD) Value() float64 {
+	return s.fast.Value() - s.slow.Value()
+}
+
+func (s *StreamingMACD) Signal() float64 {
+	return s.signal.result()
+}
+
+func (s *StreamingMACD) Histogram() float64 {
+	return s.Value() - s.Signal()
+}
+
+func (s *StreamingMACD) Ready() bool {
+	return s.slow.Ready()
+}
+
+type StreamingOBV struct {
+	prevClose float64
+	seen      bool
+	value     float64
+}
+
+func NewStreamingOBV() *StreamingOBV {
+	return &StreamingOBV{}
+}
+
+func (s *StreamingOBV) Update(candle Candle) {
+	if s.seen {
+		if candle.C > s.prevClose {
+			s.value += candle.V
+		} else if candle.C < s.prevClose {
+			s.value -= candle.V
+		}
+	}
+	s.prevClose, s.seen = candle.C, true
+}
+
+func (s *StreamingOBV) Value() float64 {
+	if !s.seen {
+		return math.NaN()
+	}
+	return s.value
+}
+
+func (s *StreamingOBV) Ready() bool {
+	return s.seen
+}
+
+// Warmup feeds the closed candles of pair between from and to into the indicators. The candle
+// that is still open is left for PollCandles, which sends it once it closes. Warmup returns
+// the start of the bar after the last fed candle, or from if there is none, to pass to PollCandles
+// as since.
+func (i *Indicator) Warmup(pair string, limit int, from, to time.Time, indicators ...StreamingIndicator) (time.Time, error) {
+	candles, err := i.GetCandles(pair, limit, from, to)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Indicator_Warmup -> %w", err)
+	}
+
+	barDuration := time.Duration(limit) * time.Minute
+	now := i.now()
+	next := from
+	for _, candle := range candles {
+		start := time.UnixMilli(candle.T)
+		if start.Add(barDuration).After(now) {
+			continue
+		}
+		for _, indicator := range indicators {
+			indicator.Update(candle)
+		}
+		if end := start.Add(barDuration); end.After(next) {
+			next = end
+		}
+	}
+	return next, nil
+}
+
+// Stream feeds every candle received from candles into the indicators and then calls onUpdate,
+// until candles is closed or ctx is done.
+func Stream(ctx context.Context, candles <-chan Candle, onUpdate func(Candle), indicators ...StreamingIndicator) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case candle, ok := <-candles:
+			if !ok {
+				return nil
+			}
+			for _, indicator := range indicators {
+				indicator.Update(candle)
+			}
+			if onUpdate != nil {
+				onUpdate(candle)
+			}
+		}
+	}
+}
+
+// PollCandles requests the candles of pair every interval and sends the candles that closed
+// since the previous request, starting from since. The channel is closed when ctx is done.
+// Request errors are passed to onError, if it is set, and the request is retried on the next tick.
+func PollCandles(ctx context.Context, exchange Exchanger, pair string, resolution int, since time.Time, interval time.Duration, onError func(error)) <-chan Candle {
+	res := make(chan Candle)
+	barDuration := time.Duration(resolution) * time.Minute
+
+	go func() {
+		defer close(res)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		next := since
+		for {
+			now := time.Now()
+			candlesHistory, err := exchange.GetCandlesHistory(pair, resolution, next, now)
+			if err != nil && onError != nil {
+				onError(fmt.Errorf("PollCandles -> %w", err))
+			}
+
+			sortCandles(candlesHistory.Candles)
+			for _, candle := range candlesHistory.Candles {
+				start := time.UnixMilli(candle.T)
+				if start.Before(next) || start.Add(barDuration).After(now) {
+					continue
+				}
+				select {
+				case res <- candle:
+					next = start.Add(barDuration)
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return res
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"io"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func referenceCandles() []Candle {
+	res := make([]Candle, 0, len(referencePrices))
+	for i, price := range referencePrices {
+		res = append(res, Candle{T: int64(i) * 60000, O: price - 0.1, H: price + 0.3, L: price - 0.4, C: price, V: float64(i%5 + 1)})
+	}
+	return res
+}
+
+func streamValues(indicator StreamingIndicator, candles []Candle) []float64 {
+	res := make([]float64, 0, len(candles))
+	for _, candle := range candles {
+		indicator.Update(candle)
+		res = append(res, indicator.Value())
+	}
+	return res
+}
+
+func TestStreamingIndicators(t *testing.T) {
+	candles := referenceCandles()
+	closes := Prices(candles, SourceClose)
+
+	sma, err := NewStreamingSMA(5, SourceClose)
+	assert.NoError(t, err)
+	assertSeries(t, simpleAverage(closes, 5), streamValues(sma, candles), 1e-9)
+
+	wma, err := NewStreamingWMA(5, SourceClose)
+	assert.NoError(t, err)
+	assertSeries(t, calculateWMA(closes, 5), streamValues(wma, candles), 1e-9)
+
+	ema, err := NewStreamingEMA(5, SourceClose)
+	assert.NoError(t, err)
+	assertSeries(t, exponentialAverage(closes, 5), streamValues(ema, candles), 1e-9)
+
+	rsi, err := NewStreamingRSI(14, SourceClose)
+	assert.NoError(t, err)
+	assertSeries(t, calculateRSI(closes, 14), streamValues(rsi, candles), 1e-9)
+
+	atr, err := NewStreamingATR(14)
+	assert.NoError(t, err)
+	assertSeries(t, calculateATR(candles, 14), streamValues(atr, candles), 1e-9)
+
+	assertSeries(t, append([]float64{0}, calculateOBV(candles)[1:]...), streamValues(NewStreamingOBV(), candles), 1e-9)
+	assert.True(t, math.IsNaN(NewStreamingOBV().Value()))
+}
+
+func TestStreamingMACD(t *testing.T) {
+	candles := referenceCandles()
+	expected := calculateMACD(Prices(candles, SourceClose), 5, 10, 4)
+
+	macd, err := NewStreamingMACD(5, 10, 4, SourceClose)
+	assert.NoError(t, err)
+
+	var signal, histogram []float64
+	for _, candle := range candles {
+		macd.Update(candle)
+		signal = append(signal, macd.Signal())
+		histogram = append(histogram, macd.Histogram())
+	}
+	assert.InDelta(t, expected.MACD[len(candles)-1], macd.Value(), 1e-9)
+	assertSeries(t, expected.Signal, signal, 1e-9)
+	assertSeries(t, expected.Histogram, histogram, 1e-9)
+
+	_, err = NewStreamingMACD(10, 5, 4, SourceClose)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestNewStreamingIndicators_invalidPeriod(t *testing.T) {
+	_, err := NewStreamingSMA(0, SourceClose)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewStreamingWMA(0, SourceClose)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewStreamingEMA(0, SourceClose)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewStreamingRSI(0, SourceClose)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewStreamingATR(0)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewStreamingMACD(1, 2, 0, SourceClose)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
+
+func TestIndicator_Warmup(t *testing.T) {
+	indicator := NewIndicator(NewExmo(Test()))
+	sma, _ := NewStreamingSMA(3, SourceClose)
+
+	_, err := indicator.Warmup("ADA_BTC", 30, time.Unix(1701289470, 0), time.Unix(1701293070, 0), sma)
+	assert.NoError(t, err)
+	assert.True(t, sma.Ready())
+	assert.Equal(t, 2.0, sma.Value())
+
+	_, err = indicator.Warmup("BTC_USD", 30, time.Unix(1701289470, 0), time.Unix(1701293070, 0), sma)
+	assert.Error(t, err)
+}
+
+func TestIndicator_Warmup_openCandle(t *testing.T) {
+	exchange := candlesExchange(`[{"t":0,"c":1},{"t":1800000,"c":3},{"t":3600000,"c":100}]`)
+	indicator := NewIndicator(exchange)
+	indicator.now = func() time.Time { return time.UnixMilli(3660000) }
+	sma, _ := NewStreamingSMA(2, SourceClose)
+
+	next, err := indicator.Warmup("BTC_USD", 30, time.Unix(0, 0), time.Unix(3660, 0), sma)
+
+	// The candle that opened at 3600000 is still open, PollCandles sends it from next.
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, sma.Value())
+	assert.True(t, time.UnixMilli(3600000).Equal(next))
+
+	next, err = NewIndicator(candlesExchange(`[]`)).Warmup("BTC_USD", 30, time.Unix(0, 0), time.Unix(3660, 0), sma)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(0, 0), next)
+}
+
+func TestStream(t *testing.T) {
+	sma, _ := NewStreamingSMA(2, SourceClose)
+	candles := make(chan Candle, 3)
+	candles <- Candle{C: 1}
+	candles <- Candle{C: 2}
+	candles <- Candle{C: 4}
+	close(candles)
+
+	var values []float64
+	err := Stream(context.Background(), candles, func(Candle) { values = append(values, sma.Value()) }, sma)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 1.5, 3}, values, 1e-9)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, Stream(ctx, make(chan Candle), nil, sma), context.Canceled)
+}
+
+func TestPollCandles(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	closed := now.Add(-2 * time.Minute).UnixMilli()
+	open := now.UnixMilli()
+	exmo := NewExmo(WithRequester(RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		return []byte(`{"candles":[{"t":` + strconv.FormatInt(open, 10) + `,"c":2},{"t":` + strconv.FormatInt(closed, 10) + `,"c":1}]}`), nil
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	candles := PollCandles(ctx, exmo, "ADA_BTC", 1, now.Add(-time.Hour), time.Millisecond, nil)
+
+	assert.Equal(t, Candle{T: closed, C: 1}, <-candles)
+	cancel()
+	for range candles {
+	}
+}
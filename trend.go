@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type DMIResult struct {
+	PlusDI  []float64
+	MinusDI []float64
+	ADX     []float64
+}
+
+// IchimokuResult holds the Ichimoku lines aligned to the candles. SenkouA and SenkouB are shifted
+// forward by the displacement, so they are longer than the candles by the displacement. Chikou is
+// the close shifted back by the displacement.
+type IchimokuResult struct {
+	Tenkan  []float64
+	Kijun   []float64
+	SenkouA []float64
+	SenkouB []float64
+	Chikou  []float64
+}
+
+func (i *Indicator) DMI(pair string, limit, period int, from, to time.Time) (DMIResult, error) {
+	res, err := i.DMISeries(pair, limit, period, from, to)
+	if err != nil {
+		return DMIResult{}, fmt.Errorf("Indicator_DMI -> %w", err)
+	}
+	return DMIResult{PlusDI: res["plus_di"].Values(), MinusDI: res["minus_di"].Values(), ADX: res["adx"].Values()}, nil
+}
+
+// DMISeries is DMI with the "plus_di", "minus_di" and "adx" lines paired with their candles.
+func (i *Indicator) DMISeries(pair string, limit, period int, from, to time.Time) (SeriesOutput, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_DMISeries -> %w", ErrInvalidPeriod)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		dmi := calculateDMI(candles, period)
+		return Output{"plus_di": dmi.PlusDI, "minus_di": dmi.MinusDI, "adx": dmi.ADX}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_DMISeries -> %w", err)
+	}
+	return res, nil
+}
+
+func (i *Indicator) ParabolicSAR(pair string, limit int, step, maxStep float64, from, to time.Time) ([]float64, error) {
+	res, err := i.ParabolicSARSeries(pair, limit, step, maxStep, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_ParabolicSAR -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+// ParabolicSARSeries is ParabolicSAR with every value paired with its candle.
+func (i *Indicator) ParabolicSARSeries(pair string, limit int, step, maxStep float64, from, to time.Time) (Series, error) {
+	if step <= 0 || maxStep < step {
+		return nil, fmt.Errorf("Indicator_ParabolicSARSeries -> step must be positive and not above max step: %w", ErrInvalidParams)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return valueOutput(calculateParabolicSAR(candles, step, maxStep))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_ParabolicSARSeries -> %w", err)
+	}
+	return res[ValueLine], nil
+}
+
+// Ichimoku uses the kijun period as the displacement, as in the classic 9, 26, 52 setup.
+func (i *Indicator) Ichimoku(pair string, limit, tenkan, kijun, senkouB int, from, to time.Time) (IchimokuResult, error) {
+	res, err := i.IchimokuSeries(pair, limit, tenkan, kijun, senkouB, from, to)
+	if err != nil {
+		return IchimokuResult{}, fmt.Errorf("Indicator_Ichimoku -> %w", err)
+	}
+	return IchimokuResult{
+		Tenkan:  res["tenkan"].Values(),
+		Kijun:   res["kijun"].Values(),
+		SenkouA: res["senkou_a"].Values(),
+		SenkouB: res["senkou_b"].Values(),
+		Chikou:  res["chikou"].Values(),
+	}, nil
+}
+
+// IchimokuSeries is Ichimoku with the "tenkan", "kijun", "senkou_a", "senkou_b" and "chikou" lines
+// paired with their bars. The displaced Senkou lines continue the resolution grid beyond the candles.
+func (i *Indicator) IchimokuSeries(pair string, limit, tenkan, kijun, senkouB int, from, to time.Time) (SeriesOutput, error) {
+	if tenkan <= 0 || kijun <= 0 || senkouB <= 0 {
+		return nil, fmt.Errorf("Indicator_IchimokuSeries -> %w", ErrInvalidPeriod)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		ichimoku := calculateIchimoku(candles, tenkan, kijun, senkouB, kijun)
+		return Output{
+			"tenkan":   ichimoku.Tenkan,
+			"kijun":    ichimoku.Kijun,
+			"senkou_a": ichimoku.SenkouA,
+			"senkou_b": ichimoku.SenkouB,
+			"chikou":   ichimoku.Chikou,
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_IchimokuSeries -> %w", err)
+	}
+	return res, nil
+}
+
+// calculateDMI follows Wilder: the directional movements and the true range are Wilder averages
+// starting from the second candle, and ADX is the Wilder average of DX.
+func calculateDMI(candles []Candle, period int) DMIResult {
+	n := len(candles)
+	plusDM, minusDM, tr := nanSeries(n), nanSeries(n), nanSeries(n)
+	ranges := trueRange(candles)
+	for i := 1; i < n; i++ {
+		up, down := candles[i].H-candles[i-1].H, candles[i-1].L-candles[i].L
+		plusDM[i], minusDM[i], tr[i] = 0, 0, ranges[i]
+		if up > down && up > 0 {
+			plusDM[i] = up
+		}
+		if down > up && down > 0 {
+			minusDM[i] = down
+		}
+	}
+
+	avgPlus, avgMinus, avgTR := wilderAverage(plusDM, period), wilderAverage(minusDM, period), wilderAverage(tr, period)
+	plusDI, minusDI, dx := nanSeries(n), nanSeries(n), nanSeries(n)
+	for i := range candles {
+		if math.IsNaN(avgTR[i]) || avgTR[i] == 0 {
+			continue
+		}
+		plusDI[i], minusDI[i] = 100*avgPlus[i]/avgTR[i], 100*avgMinus[i]/avgTR[i]
+		dx[i] = 0
+		if sum := plusDI[i] + minusDI[i]; sum != 0 {
+			dx[i] = 100 * math.Abs(plusDI[i]-minusDI[i]) / sum
+		}
+	}
+	return DMIResult{PlusDI: plusDI, MinusDI: minusDI, ADX: wilderAverage(dx, period)}
+}
+
+// calculateParabolicSAR starts in the direction of the first two closes. The acceleration factor
+// grows by step with every new extreme point, up to maxStep.
+func calculateParabolicSAR(candles []Candle, step, maxStep float64) []float64 {
+	res := nanSeries(len(candles))
+	if len(candles) < 2 {
+		return res
+	}
+
+	up := candles[1].C >= candles[0].C
+	sar, extreme, factor := candles[0].H, candles[0].L, step
+	if up {
+		sar, extreme = candles[0].L, candles[0].H
+	}
+
+	for i := 1; i < len(candles); i++ {
+		sar += factor * (extreme - sar)
+		prev := candles[i-1]
+		if up {
+			sar = math.Min(sar, prev.L)
+			if i > 1 {
+				sar = math.Min(sar, candles[i-2].L)
+			}
+			if candles[i].L < sar {
+				up, sar, extreme, factor = false, extreme, candles[i].L, step
+			} else if candles[i].H > extreme {
+				extreme, factor = candles[i].H, math.Min(factor+step, maxStep)
+			}
+		} else {
+			sar = math.Max(sar, prev.H)
+			if i > 1 {
+				sar = math.Max(sar, candles[i-2].H)
+			}
+			if candles[i].H > sar {
+				up, sar, extreme, factor = true, extreme, candles[i].H, step
+			} else if candles[i].L < extreme {
+				extreme, factor = candles[i].L, math.Min(factor+step, maxStep)
+			}
+		}
+		res[i] = sar
+	}
+	return res
+}
+
+func calculateIchimoku(candles []Candle, tenkanPeriod, kijunPeriod, senkouBPeriod, displacement int) IchimokuResult {
+	n := len(candles)
+	midpoint := func(period int) []float64 {
+		res := nanSeries(n)
+		for i := period - 1; i < n; i++ {
+			res[i] = (highest(candles, i, period) + lowest(candles, i, period)) / 2
+		}
+		return res
+	}
+
+	tenkan, kijun, senkouBase := midpoint(tenkanPeriod), midpoint(kijunPeriod), midpoint(senkouBPeriod)
+	senkouA, senkouB := nanSeries(n+displacement), nanSeries(n+displacement)
+	chikou := nanSeries(n)
+	for i := range candles {
+		senkouA[i+displacement] = (tenkan[i] + kijun[i]) / 2
+		senkouB[i+displacement] = senkouBase[i]
+		if i >= displacement {
+			chikou[i-displacement] = candles[i].C
+		}
+	}
+	return IchimokuResult{Tenkan: tenkan, Kijun: kijun, SenkouA: senkouA, SenkouB: senkouB, Chikou: chikou}
+}
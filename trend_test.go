@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func risingCandles(n int) []Candle {
+	res := make([]Candle, 0, n)
+	for i := 0; i < n; i++ {
+		res = append(res, Candle{H: float64(i + 2), L: float64(i), C: float64(i + 1)})
+	}
+	return res
+}
+
+func Test_calculateDMI(t *testing.T) {
+	nan := math.NaN()
+
+	result := calculateDMI(risingCandles(5), 2)
+
+	assertSeries(t, []float64{nan, nan, 50, 50, 50}, result.PlusDI, 1e-9)
+	assertSeries(t, []float64{nan, nan, 0, 0, 0}, result.MinusDI, 1e-9)
+	assertSeries(t, []float64{nan, nan, nan, 100, 100}, result.ADX, 1e-9)
+}
+
+func Test_calculateParabolicSAR(t *testing.T) {
+	candles := append(risingCandles(5), Candle{H: 3, L: 0.1, C: 0.5})
+
+	result := calculateParabolicSAR(candles, 0.02, 0.2)
+
+	assertSeries(t, []float64{math.NaN(), 0, 0, 0.24, 0.6208, 6}, result, 1e-9)
+	assertSeries(t, []float64{math.NaN()}, calculateParabolicSAR(candles[:1], 0.02, 0.2), 0)
+}
+
+func Test_calculateIchimoku(t *testing.T) {
+	nan := math.NaN()
+
+	result := calculateIchimoku(risingCandles(4), 1, 2, 3, 2)
+
+	assertSeries(t, []float64{1, 2, 3, 4}, result.Tenkan, 0)
+	assertSeries(t, []float64{nan, 1.5, 2.5, 3.5}, result.Kijun, 0)
+	assertSeries(t, []float64{nan, nan, nan, 1.75, 2.75, 3.75}, result.SenkouA, 0)
+	assertSeries(t, []float64{nan, nan, nan, nan, 2, 3}, result.SenkouB, 0)
+	assertSeries(t, []float64{3, 4, nan, nan}, result.Chikou, 0)
+}
+
+func TestIndicator_trend(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"h":2,"l":0,"c":1},{"h":3,"l":1,"c":2},{"h":4,"l":2,"c":3},{"h":5,"l":3,"c":4}]`))
+	from, to := time.Unix(1701289470, 0), time.Unix(1701300270, 0)
+
+	dmi, err := indicator.DMI("ADA_BTC", 30, 2, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 50, 50}, dmi.PlusDI, 1e-9)
+	_, err = indicator.DMI("ADA_BTC", 30, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	sar, err := indicator.ParabolicSAR("ADA_BTC", 30, 0.02, 0.2, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, sar, 4)
+	_, err = indicator.ParabolicSAR("ADA_BTC", 30, 0.3, 0.2, from, to)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	ichimoku, err := indicator.Ichimoku("ADA_BTC", 30, 1, 2, 3, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, ichimoku.SenkouA, 6)
+	_, err = indicator.Ichimoku("ADA_BTC", 30, 1, 0, 3, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	_, err = NewIndicator(NewExmo(Test())).DMI("BTC_USD", 30, 2, from, to)
+	assert.Error(t, err)
+}
+
+func TestIndicator_trendSeries(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"t":0,"h":2,"l":0,"c":1},{"t":1800000,"h":3,"l":1,"c":2},{"t":3600000,"h":4,"l":2,"c":3},{"t":5400000,"h":5,"l":3,"c":4}]`))
+	from, to := time.Unix(0, 0), time.Unix(7200, 0)
+
+	dmi, err := indicator.DMISeries("ADA_BTC", 30, 2, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 50, 50}, dmi["plus_di"].Values(), 1e-9)
+	_, err = indicator.DMISeries("ADA_BTC", 30, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	sar, err := indicator.ParabolicSARSeries("ADA_BTC", 30, 0.02, 0.2, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, sar, 4)
+	_, err = indicator.ParabolicSARSeries("ADA_BTC", 30, 0.3, 0.2, from, to)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	ichimoku, err := indicator.IchimokuSeries("ADA_BTC", 30, 1, 2, 3, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, ichimoku["senkou_a"], 6)
+	assert.Equal(t, time.Unix(9000, 0), ichimoku["senkou_a"][5].Start)
+	assert.Len(t, ichimoku["tenkan"], 4)
+	_, err = indicator.IchimokuSeries("ADA_BTC", 30, 1, 0, 3, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
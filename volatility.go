@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type Bands struct {
+	Upper  []float64
+	Middle []float64
+	Lower  []float64
+}
+
+func (i *Indicator) BollingerBands(pair string, limit, period int, k float64, from, to time.Time) (Bands, error) {
+	res, err := i.BollingerBandsSeries(pair, limit, period, k, from, to)
+	if err != nil {
+		return Bands{}, fmt.Errorf("Indicator_BollingerBands -> %w", err)
+	}
+	return seriesBands(res), nil
+}
+
+// BollingerBandsSeries is BollingerBands with the "upper", "middle" and "lower" lines paired with their candles.
+func (i *Indicator) BollingerBandsSeries(pair string, limit, period int, k float64, from, to time.Time) (SeriesOutput, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_BollingerBandsSeries -> %w", ErrInvalidPeriod)
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("Indicator_BollingerBandsSeries -> multiplier must be positive: %w", ErrInvalidParams)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return bandsOutput(calculateBollingerBands(Prices(candles, i.source), period, k))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_BollingerBandsSeries -> %w", err)
+	}
+	return res, nil
+}
+
+func (i *Indicator) ATR(pair string, limit, period int, from, to time.Time) ([]float64, error) {
+	res, err := i.ATRSeries(pair, limit, period, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_ATR -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+// ATRSeries is ATR with every value paired with its candle.
+func (i *Indicator) ATRSeries(pair string, limit, period int, from, to time.Time) (Series, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_ATRSeries -> %w", ErrInvalidPeriod)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return valueOutput(calculateATR(candles, period))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_ATRSeries -> %w", err)
+	}
+	return res[ValueLine], nil
+}
+
+func (i *Indicator) KeltnerChannels(pair string, limit, period, atrPeriod int, multiplier float64, from, to time.Time) (Bands, error) {
+	res, err := i.KeltnerChannelsSeries(pair, limit, period, atrPeriod, multiplier, from, to)
+	if err != nil {
+		return Bands{}, fmt.Errorf("Indicator_KeltnerChannels -> %w", err)
+	}
+	return seriesBands(res), nil
+}
+
+// KeltnerChannelsSeries is KeltnerChannels with the "upper", "middle" and "lower" lines paired with their candles.
+func (i *Indicator) KeltnerChannelsSeries(pair string, limit, period, atrPeriod int, multiplier float64, from, to time.Time) (SeriesOutput, error) {
+	if period <= 0 || atrPeriod <= 0 {
+		return nil, fmt.Errorf("Indicator_KeltnerChannelsSeries -> %w", ErrInvalidPeriod)
+	}
+	if multiplier <= 0 {
+		return nil, fmt.Errorf("Indicator_KeltnerChannelsSeries -> multiplier must be positive: %w", ErrInvalidParams)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return bandsOutput(calculateKeltnerChannels(candles, Prices(candles, i.source), period, atrPeriod, multiplier))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_KeltnerChannelsSeries -> %w", err)
+	}
+	return res, nil
+}
+
+func (i *Indicator) DonchianChannels(pair string, limit, period int, from, to time.Time) (Bands, error) {
+	res, err := i.DonchianChannelsSeries(pair, limit, period, from, to)
+	if err != nil {
+		return Bands{}, fmt.Errorf("Indicator_DonchianChannels -> %w", err)
+	}
+	return seriesBands(res), nil
+}
+
+// DonchianChannelsSeries is DonchianChannels with the "upper", "middle" and "lower" lines paired with their candles.
+func (i *Indicator) DonchianChannelsSeries(pair string, limit, period int, from, to time.Time) (SeriesOutput, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_DonchianChannelsSeries -> %w", ErrInvalidPeriod)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return bandsOutput(calculateDonchianChannels(candles, period))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_DonchianChannelsSeries -> %w", err)
+	}
+	return res, nil
+}
+
+// calculateBollingerBands returns the simple average of data plus and minus k population
+// standard deviations of the same window.
+func calculateBollingerBands(data []float64, period int, k float64) Bands {
+	middle := simpleAverage(data, period)
+	upper, lower := nanSeries(len(data)), nanSeries(len(data))
+	for i := range data {
+		if math.IsNaN(middle[i]) {
+			continue
+		}
+
+		var variance float64
+		for _, value := range data[i-period+1 : i+1] {
+			variance += (value - middle[i]) * (value - middle[i])
+		}
+		deviation := math.Sqrt(variance / float64(period))
+		upper[i], lower[i] = middle[i]+k*deviation, middle[i]-k*deviation
+	}
+	return Bands{Upper: upper, Middle: middle, Lower: lower}
+}
+
+// trueRange returns the true range of every candle. The first candle has no previous close,
+// so its range is high minus low.
+func trueRange(candles []Candle) []float64 {
+	res := make([]float64, len(candles))
+	for i, candle := range candles {
+		res[i] = candle.H - candle.L
+		if i > 0 {
+			prevClose := candles[i-1].C
+			res[i] = math.Max(res[i], math.Max(math.Abs(candle.H-prevClose), math.Abs(candle.L-prevClose)))
+		}
+	}
+	return res
+}
+
+// calculateATR is the Wilder average of the true range.
+func calculateATR(candles []Candle, period int) []float64 {
+	return wilderAverage(trueRange(candles), period)
+}
+
+// calculateKeltnerChannels returns the exponential average of data plus and minus
+// multiplier average true ranges.
+func calculateKeltnerChannels(candles []Candle, data []float64, period, atrPeriod int, multiplier float64) Bands {
+	middle := exponentialAverage(data, period)
+	atr := calculateATR(candles, atrPeriod)
+	upper, lower := make([]float64, len(data)), make([]float64, len(data))
+	for i := range data {
+		upper[i], lower[i] = middle[i]+multiplier*atr[i], middle[i]-multiplier*atr[i]
+	}
+	return Bands{Upper: upper, Middle: middle, Lower: lower}
+}
+
+// calculateDonchianChannels returns the highest high, the lowest low and their midpoint over period.
+func calculateDonchianChannels(candles []Candle, period int) Bands {
+	upper, middle, lower := nanSeries(len(candles)), nanSeries(len(candles)), nanSeries(len(candles))
+	for i := period - 1; i < len(candles); i++ {
+		upper[i], lower[i] = highest(candles, i, period), lowest(candles, i, period)
+		middle[i] = (upper[i] + lower[i]) / 2
+	}
+	return Bands{Upper: upper, Middle: middle, Lower: lower}
+}
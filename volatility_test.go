@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var volatilityCandles = []Candle{
+	{O: 2, H: 3, L: 1, C: 2},
+	{O: 2, H: 5, L: 2, C: 4},
+	{O: 4, H: 4, L: 3, C: 3},
+	{O: 3, H: 7, L: 3, C: 6},
+}
+
+func Test_calculateBollingerBands(t *testing.T) {
+	nan := math.NaN()
+
+	result := calculateBollingerBands([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, 2)
+
+	assertSeries(t, []float64{nan, nan, nan, nan, nan, nan, nan, 5}, result.Middle, 1e-9)
+	assertSeries(t, []float64{nan, nan, nan, nan, nan, nan, nan, 9}, result.Upper, 1e-9)
+	assertSeries(t, []float64{nan, nan, nan, nan, nan, nan, nan, 1}, result.Lower, 1e-9)
+}
+
+func Test_trueRange(t *testing.T) {
+	assert.Equal(t, []float64{2, 3, 1, 4}, trueRange(volatilityCandles))
+}
+
+func Test_calculateATR(t *testing.T) {
+	nan := math.NaN()
+
+	assertSeries(t, []float64{nan, 2.5, 1.75, 2.875}, calculateATR(volatilityCandles, 2), 1e-9)
+}
+
+func Test_calculateKeltnerChannels(t *testing.T) {
+	nan := math.NaN()
+
+	result := calculateKeltnerChannels(volatilityCandles, Prices(volatilityCandles, SourceClose), 2, 2, 2)
+
+	assertSeries(t, []float64{nan, 3, 3, 5}, result.Middle, 1e-9)
+	assertSeries(t, []float64{nan, 8, 6.5, 10.75}, result.Upper, 1e-9)
+	assertSeries(t, []float64{nan, -2, -0.5, -0.75}, result.Lower, 1e-9)
+}
+
+func Test_calculateDonchianChannels(t *testing.T) {
+	nan := math.NaN()
+
+	result := calculateDonchianChannels(volatilityCandles, 3)
+
+	assertSeries(t, []float64{nan, nan, 5, 7}, result.Upper, 0)
+	assertSeries(t, []float64{nan, nan, 1, 2}, result.Lower, 0)
+	assertSeries(t, []float64{nan, nan, 3, 4.5}, result.Middle, 0)
+}
+
+func TestIndicator_volatility(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"o":2,"h":3,"l":1,"c":2},{"o":2,"h":5,"l":2,"c":4},{"o":4,"h":4,"l":3,"c":3},{"o":3,"h":7,"l":3,"c":6}]`))
+	from, to := time.Unix(1701289470, 0), time.Unix(1701300270, 0)
+
+	bollinger, err := indicator.BollingerBands("ADA_BTC", 30, 2, 2, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, bollinger.Upper, 4)
+	_, err = indicator.BollingerBands("ADA_BTC", 30, 2, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	atr, err := indicator.ATR("ADA_BTC", 30, 2, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, calculateATR(volatilityCandles, 2), atr, 0)
+	_, err = indicator.ATR("ADA_BTC", 30, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	keltner, err := indicator.KeltnerChannels("ADA_BTC", 30, 2, 2, 2, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, keltner.Lower, 4)
+	_, err = indicator.KeltnerChannels("ADA_BTC", 30, 2, 0, 2, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	donchian, err := indicator.DonchianChannels("ADA_BTC", 30, 3, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 3, 4.5}, donchian.Middle, 0)
+	_, err = NewIndicator(NewExmo(Test())).DonchianChannels("BTC_USD", 30, 3, from, to)
+	assert.Error(t, err)
+}
+
+func TestIndicator_volatilitySeries(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"t":0,"o":2,"h":3,"l":1,"c":2},{"t":1800000,"o":2,"h":5,"l":2,"c":4},{"t":3600000,"o":4,"h":4,"l":3,"c":3},{"t":5400000,"o":3,"h":7,"l":3,"c":6}]`))
+	from, to := time.Unix(0, 0), time.Unix(7200, 0)
+
+	bollinger, err := indicator.BollingerBandsSeries("ADA_BTC", 30, 2, 2, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, bollinger["upper"], 4)
+	assert.Equal(t, time.Unix(5400, 0), bollinger["middle"][3].Start)
+	_, err = indicator.BollingerBandsSeries("ADA_BTC", 30, 2, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	atr, err := indicator.ATRSeries("ADA_BTC", 30, 2, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, calculateATR(volatilityCandles, 2), atr.Values(), 0)
+	_, err = indicator.ATRSeries("ADA_BTC", 30, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	keltner, err := indicator.KeltnerChannelsSeries("ADA_BTC", 30, 2, 2, 2, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, keltner["lower"], 4)
+	_, err = indicator.KeltnerChannelsSeries("ADA_BTC", 30, 2, 2, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	donchian, err := indicator.DonchianChannelsSeries("ADA_BTC", 30, 3, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 3, 4.5}, donchian["middle"].Values(), 0)
+	_, err = NewIndicator(NewExmo(Test())).DonchianChannelsSeries("BTC_USD", 30, 3, from, to)
+	assert.Error(t, err)
+}
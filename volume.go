@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// VWAP returns the volume weighted average typical price anchored to the start of each day in loc.
+func (i *Indicator) VWAP(pair string, limit int, from, to time.Time, loc *time.Location) ([]float64, error) {
+	res, err := i.VWAPSeries(pair, limit, from, to, loc)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_VWAP -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+// VWAPSeries is VWAP with every value paired with its candle.
+func (i *Indicator) VWAPSeries(pair string, limit int, from, to time.Time, loc *time.Location) (Series, error) {
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return valueOutput(calculateVWAP(candles, loc))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_VWAPSeries -> %w", err)
+	}
+	return res[ValueLine], nil
+}
+
+func (i *Indicator) RollingVWAP(pair string, limit, period int, from, to time.Time) ([]float64, error) {
+	res, err := i.RollingVWAPSeries(pair, limit, period, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_RollingVWAP -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+// RollingVWAPSeries is RollingVWAP with every value paired with its candle.
+func (i *Indicator) RollingVWAPSeries(pair string, limit, period int, from, to time.Time) (Series, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_RollingVWAPSeries -> %w", ErrInvalidPeriod)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return valueOutput(calculateRollingVWAP(candles, period))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_RollingVWAPSeries -> %w", err)
+	}
+	return res[ValueLine], nil
+}
+
+// TradesVWAP returns the volume weighted average price of the latest trades of pair.
+func (i *Indicator) TradesVWAP(pair string) (float64, error) {
+	trades, err := i.exchange.GetTrades(pair)
+	if err != nil {
+		return 0, fmt.Errorf("Indicator_TradesVWAP -> %w", err)
+	}
+
+	vwap, err := calculateTradesVWAP(trades[pair])
+	if err != nil {
+		return 0, fmt.Errorf("Indicator_TradesVWAP -> %w", err)
+	}
+	return vwap, nil
+}
+
+func (i *Indicator) OBV(pair string, limit int, from, to time.Time) ([]float64, error) {
+	res, err := i.OBVSeries(pair, limit, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_OBV -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+// OBVSeries is OBV with every value paired with its candle.
+func (i *Indicator) OBVSeries(pair string, limit int, from, to time.Time) (Series, error) {
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return valueOutput(calculateOBV(candles))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_OBVSeries -> %w", err)
+	}
+	return res[ValueLine], nil
+}
+
+func (i *Indicator) MFI(pair string, limit, period int, from, to time.Time) ([]float64, error) {
+	res, err := i.MFISeries(pair, limit, period, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_MFI -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+// MFISeries is MFI with every value paired with its candle.
+func (i *Indicator) MFISeries(pair string, limit, period int, from, to time.Time) (Series, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("Indicator_MFISeries -> %w", ErrInvalidPeriod)
+	}
+
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return valueOutput(calculateMFI(candles, period))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_MFISeries -> %w", err)
+	}
+	return res[ValueLine], nil
+}
+
+func (i *Indicator) AccumulationDistribution(pair string, limit int, from, to time.Time) ([]float64, error) {
+	res, err := i.AccumulationDistributionSeries(pair, limit, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_AccumulationDistribution -> %w", err)
+	}
+	return res.Values(), nil
+}
+
+// AccumulationDistributionSeries is AccumulationDistribution with every value paired with its candle.
+func (i *Indicator) AccumulationDistributionSeries(pair string, limit int, from, to time.Time) (Series, error) {
+	res, err := i.candleSeries(pair, limit, from, to, func(candles []Candle) Output {
+		return valueOutput(calculateAccumulationDistribution(candles))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Indicator_AccumulationDistributionSeries -> %w", err)
+	}
+	return res[ValueLine], nil
+}
+
+// calculateVWAP resets the cumulative sums at midnight in loc (UTC if nil).
+// Values are NaN until the session has traded any volume.
+func calculateVWAP(candles []Candle, loc *time.Location) []float64 {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	res := nanSeries(len(candles))
+	var session time.Time
+	var amount, volume float64
+	for i, candle := range candles {
+		start := bucketStart(time.UnixMilli(candle.T).In(loc), Day)
+		if !start.Equal(session) {
+			session, amount, volume = start, 0, 0
+		}
+
+		amount += SourceTypical.Price(candle) * candle.V
+		volume += candle.V
+		if volume > 0 {
+			res[i] = amount / volume
+		}
+	}
+	return res
+}
+
+func calculateRollingVWAP(candles []Candle, period int) []float64 {
+	res := nanSeries(len(candles))
+	var amount, volume float64
+	for i, candle := range candles {
+		amount += SourceTypical.Price(candle) * candle.V
+		volume += candle.V
+		if i >= period {
+			amount -= SourceTypical.Price(candles[i-period]) * candles[i-period].V
+			volume -= candles[i-period].V
+		}
+		if i >= period-1 && volume > 0 {
+			res[i] = amount / volume
+		}
+	}
+	return res
+}
+
+func calculateTradesVWAP(trades []Pair) (float64, error) {
+	var amount, volume float64
+	for _, trade := range trades {
+		price, err := strconv.ParseFloat(trade.Price, 64)
+		if err != nil {
+			return 0, err
+		}
+		quantity, err := strconv.ParseFloat(trade.Quantity, 64)
+		if err != nil {
+			return 0, err
+		}
+		amount += price * quantity
+		volume += quantity
+	}
+
+	if volume == 0 {
+		return math.NaN(), nil
+	}
+	return amount / volume, nil
+}
+
+func calculateOBV(candles []Candle) []float64 {
+	res := make([]float64, len(candles))
+	for i := 1; i < len(candles); i++ {
+		res[i] = res[i-1]
+		if candles[i].C > candles[i-1].C {
+			res[i] += candles[i].V
+		} else if candles[i].C < candles[i-1].C {
+			res[i] -= candles[i].V
+		}
+	}
+	return res
+}
+
+// calculateMFI is a volume weighted RSI of the typical price. The first value is at index period.
+func calculateMFI(candles []Candle, period int) []float64 {
+	res := nanSeries(len(candles))
+	positive, negative := make([]float64, len(candles)), make([]float64, len(candles))
+	for i := 1; i < len(candles); i++ {
+		price, prevPrice := SourceTypical.Price(candles[i]), SourceTypical.Price(candles[i-1])
+		if price > prevPrice {
+			positive[i] = price * candles[i].V
+		} else if price < prevPrice {
+			negative[i] = price * candles[i].V
+		}
+	}
+
+	var positiveSum, negativeSum float64
+	for i := 1; i < len(candles); i++ {
+		positiveSum += positive[i]
+		negativeSum += negative[i]
+		if i > period {
+			positiveSum -= positive[i-period]
+			negativeSum -= negative[i-period]
+		}
+		if i < period {
+			continue
+		}
+		if negativeSum == 0 {
+			res[i] = 100
+			continue
+		}
+		res[i] = 100 - 100/(1+positiveSum/negativeSum)
+	}
+	return res
+}
+
+func calculateAccumulationDistribution(candles []Candle) []float64 {
+	res := make([]float64, len(candles))
+	var sum float64
+	for i, candle := range candles {
+		if candle.H != candle.L {
+			sum += ((candle.C - candle.L) - (candle.H - candle.C)) / (candle.H - candle.L) * candle.V
+		}
+		res[i] = sum
+	}
+	return res
+}
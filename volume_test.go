@@ -0,0 +1,130 @@
+package main
+
+import (
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var volumeCandles = []Candle{
+	{T: 1701302400000, H: 10, L: 10, C: 10, V: 1},
+	{T: 1701306000000, H: 20, L: 20, C: 20, V: 3},
+	{T: 1701388800000, H: 30, L: 30, C: 30, V: 1},
+	{T: 1701392400000, H: 25, L: 25, C: 25, V: 2},
+}
+
+func Test_calculateVWAP(t *testing.T) {
+	assertSeries(t, []float64{10, 17.5, 30, 80.0 / 3}, calculateVWAP(volumeCandles, nil), 1e-9)
+	assertSeries(t, []float64{10, 20, 22.5, 25}, calculateVWAP(volumeCandles, time.FixedZone("", -30*60)), 1e-9)
+	assertSeries(t, []float64{math.NaN()}, calculateVWAP([]Candle{{C: 1}}, nil), 0)
+}
+
+func Test_calculateRollingVWAP(t *testing.T) {
+	assertSeries(t, []float64{math.NaN(), 17.5, 22.5, 80.0 / 3}, calculateRollingVWAP(volumeCandles, 2), 1e-9)
+}
+
+func Test_calculateTradesVWAP(t *testing.T) {
+	result, err := calculateTradesVWAP(testTrades)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.25, result)
+
+	result, err = calculateTradesVWAP(nil)
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(result))
+
+	_, err = calculateTradesVWAP([]Pair{{Price: "1", Quantity: "invalid"}})
+	assert.Error(t, err)
+}
+
+func Test_calculateOBV(t *testing.T) {
+	assert.Equal(t, []float64{0, 3, 4, 2}, calculateOBV(volumeCandles))
+}
+
+func Test_calculateMFI(t *testing.T) {
+	assertSeries(t, []float64{math.NaN(), math.NaN(), 100, 37.5}, calculateMFI(volumeCandles, 2), 1e-9)
+}
+
+func Test_calculateAccumulationDistribution(t *testing.T) {
+	candles := []Candle{{H: 4, L: 0, C: 3, V: 2}, {H: 2, L: 2, C: 2, V: 5}, {H: 4, L: 2, C: 2, V: 1}}
+
+	assert.Equal(t, []float64{1, 1, 0}, calculateAccumulationDistribution(candles))
+}
+
+func TestIndicator_volume(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"t":1701302400000,"h":10,"l":10,"c":10,"v":1},{"t":1701306000000,"h":20,"l":20,"c":20,"v":3}]`))
+	from, to := time.Unix(1701289470, 0), time.Unix(1701300270, 0)
+
+	vwap, err := indicator.VWAP("ADA_BTC", 30, from, to, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, 17.5}, vwap)
+
+	rolling, err := indicator.RollingVWAP("ADA_BTC", 30, 1, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, 20}, rolling)
+	_, err = indicator.RollingVWAP("ADA_BTC", 30, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	obv, err := indicator.OBV("ADA_BTC", 30, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 3}, obv)
+
+	mfi, err := indicator.MFI("ADA_BTC", 30, 1, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 100}, mfi, 0)
+	_, err = indicator.MFI("ADA_BTC", 30, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	ad, err := indicator.AccumulationDistribution("ADA_BTC", 30, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 0}, ad)
+
+	_, err = NewIndicator(NewExmo(Test())).OBV("BTC_USD", 30, from, to)
+	assert.Error(t, err)
+}
+
+func TestIndicator_TradesVWAP(t *testing.T) {
+	indicator := NewIndicator(NewExmo(WithRequester(RequesterFunc(func(method string, url string, body io.Reader) ([]byte, error) {
+		return []byte(`{"ADA_BTC":[{"quantity":"1","price":"10"},{"quantity":"3","price":"20"}]}`), nil
+	}))))
+
+	result, err := indicator.TradesVWAP("ADA_BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, 17.5, result)
+}
+
+func TestIndicator_volumeSeries(t *testing.T) {
+	indicator := NewIndicator(candlesExchange(`[{"t":1701302400000,"h":10,"l":10,"c":10,"v":1},{"t":1701306000000,"h":20,"l":20,"c":20,"v":3}]`))
+	from, to := time.Unix(1701289470, 0), time.Unix(1701300270, 0)
+
+	vwap, err := indicator.VWAPSeries("ADA_BTC", 60, from, to, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, 17.5}, vwap.Values())
+	assert.Equal(t, time.UnixMilli(1701306000000), vwap[1].Start)
+	assert.Equal(t, time.UnixMilli(1701309600000), vwap[1].End)
+
+	rolling, err := indicator.RollingVWAPSeries("ADA_BTC", 60, 1, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, 20}, rolling.Values())
+	_, err = indicator.RollingVWAPSeries("ADA_BTC", 60, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	obv, err := indicator.OBVSeries("ADA_BTC", 60, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 3}, obv.Values())
+
+	mfi, err := indicator.MFISeries("ADA_BTC", 60, 1, from, to)
+	assert.NoError(t, err)
+	assertSeries(t, []float64{math.NaN(), 100}, mfi.Values(), 0)
+	_, err = indicator.MFISeries("ADA_BTC", 60, 0, from, to)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	ad, err := indicator.AccumulationDistributionSeries("ADA_BTC", 60, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 0}, ad.Values())
+
+	_, err = NewIndicator(NewExmo(Test())).OBVSeries("BTC_USD", 30, from, to)
+	assert.Error(t, err)
+}